@@ -0,0 +1,89 @@
+package rbt
+
+// NodeAllocation is the strategy used for allocating the nodes of a tree
+type NodeAllocation byte
+
+const (
+    // HeapAllocation allocates each node separately on the heap
+    HeapAllocation NodeAllocation = iota
+    // ArenaAllocation carves the nodes from slabs and recycles the nodes of the deleted keys
+    ArenaAllocation
+)
+
+// arenaSlabSize is the count of the nodes in a slab
+const arenaSlabSize = 1024
+
+// rbArena structure allocates nodes from slabs keeping the released nodes in a free list linked by their left child
+type rbArena struct {
+    slab []rbNode
+    free *rbNode
+}
+
+// NewRbTreeWithAllocation creates a new RbTree allocating its nodes with the given strategy and returns its address.
+// The copies of the nodes shared with a snapshot are also allocated from the arena, the shared nodes
+// themselves are never released to the arena.
+func NewRbTreeWithAllocation(allocation NodeAllocation) *RbTree {
+    tree := &RbTree{}
+    if allocation == ArenaAllocation {
+        tree.arena = &rbArena{}
+    }
+    return tree
+}
+
+// NodeAllocation returns the node allocation strategy of the tree
+func (tree *RbTree) NodeAllocation() NodeAllocation {
+    if tree.arena != nil {
+        return ArenaAllocation
+    }
+    return HeapAllocation
+}
+
+// newNode creates a new node from the arena of the tree if exists, otherwise on the heap
+func (tree *RbTree) newNode(key RbKey, value interface{}) *rbNode {
+    var node *rbNode
+    if tree.arena != nil {
+        node = tree.arena.alloc(key, value)
+    } else {
+        node = newRbNode(key, value)
+    }
+    node.augmented = tree.augmented
+    node.gen = tree.gen
+    return node
+}
+
+// releaseNode returns the node removed from the tree to the arena of the tree if exists
+func (tree *RbTree) releaseNode(node *rbNode) {
+    if tree.arena != nil {
+        tree.arena.release(node)
+    }
+}
+
+// alloc returns a recycled node if exists, otherwise the next node of the current slab
+func (arena *rbArena) alloc(key RbKey, value interface{}) *rbNode {
+    node := arena.free
+    if node != nil {
+        arena.free = node.left
+    } else {
+        if len(arena.slab) == cap(arena.slab) {
+            arena.slab = make([]rbNode, 0, arenaSlabSize)
+        }
+        arena.slab = arena.slab[:len(arena.slab) + 1]
+        node = &arena.slab[len(arena.slab) - 1]
+    }
+
+    *node = rbNode{
+        key: key,
+        value: value,
+        color: red,
+        size: 1,
+    }
+    return node
+}
+
+// release clears the node and adds it to the free list
+func (arena *rbArena) release(node *rbNode) {
+    *node = rbNode{
+        left: arena.free,
+    }
+    arena.free = node
+}
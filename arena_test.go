@@ -0,0 +1,165 @@
+package rbt
+
+import (
+    "math/rand"
+    "runtime"
+    "testing"
+)
+
+func TestArenaAllocation(t *testing.T) {
+    tree := NewRbTreeWithAllocation(ArenaAllocation)
+    if tree.NodeAllocation() != ArenaAllocation || NewRbTree().NodeAllocation() != HeapAllocation {
+        t.Fatalf("NodeAllocation() mismatch")
+    }
+
+    expected := make(map[int]int)
+    r := rand.New(rand.NewSource(21))
+    for i := 0; i < 20000; i++ {
+        k := r.Intn(3000)
+        if r.Intn(3) == 0 {
+            tree.Delete(newKey(IntKey(k)))
+            delete(expected, k)
+        } else {
+            tree.Insert(newKey(IntKey(k)), i)
+            expected[k] = i
+        }
+    }
+    checkRbTree(t, tree.root)
+    if tree.Count() != len(expected) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(expected))
+    }
+    for k, v := range expected {
+        if value, ok := tree.Get(newKey(IntKey(k))); !ok || value != v {
+            t.Fatalf("Get(%d) = %v, %v, want %d", k, value, ok, v)
+        }
+    }
+
+    for i := 0; i < 100; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+    slabs := len(tree.arena.slab)
+    for i := 0; i < 100; i++ {
+        tree.Delete(newKey(IntKey(i)))
+    }
+    for i := 0; i < 100; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+    if len(tree.arena.slab) != slabs {
+        t.Fatalf("released nodes not recycled, slab grew from %d to %d", slabs, len(tree.arena.slab))
+    }
+    checkRbTree(t, tree.root)
+
+    snapshot := tree.Snapshot()
+    shared := make(map[*rbNode]bool)
+    collectNodes(snapshot.root, shared)
+    for i := 0; i < 100; i++ {
+        tree.Delete(newKey(IntKey(i)))
+        tree.Insert(newKey(IntKey(i + 3000)), i)
+    }
+    for node := tree.arena.free; node != nil; node = node.left {
+        if shared[node] {
+            t.Fatalf("node shared with the snapshot released to the arena")
+        }
+    }
+    for i := 0; i < 100; i++ {
+        if value, ok := snapshot.Get(newKey(IntKey(i))); !ok || value != i {
+            t.Fatalf("snapshot Get(%d) = %v, %v", i, value, ok)
+        }
+    }
+}
+
+// collectNodes adds the nodes of the subtree to the set
+func collectNodes(node *rbNode, nodes map[*rbNode]bool) {
+    if node != nil {
+        nodes[node] = true
+        collectNodes(node.left, nodes)
+        collectNodes(node.right, nodes)
+    }
+}
+
+func TestArenaAfterSnapshot(t *testing.T) {
+    tree := NewRbTreeWithAllocation(ArenaAllocation)
+    for i := 0; i < 3000; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+    snapshot := tree.Snapshot()
+    shared := make(map[*rbNode]bool)
+    collectNodes(snapshot.root, shared)
+    if tree.linked() {
+        t.Fatalf("tree sharing its nodes with a snapshot is linked")
+    }
+
+    // updating every key copies every shared node once, the copies come from the arena
+    keys := make([]RbKey, 3000)
+    for i := range keys {
+        keys[i] = newKey(IntKey(i))
+    }
+    var before, after runtime.MemStats
+    runtime.ReadMemStats(&before)
+    for _, key := range keys {
+        tree.Insert(key, nil)
+    }
+    runtime.ReadMemStats(&after)
+    if mallocs := after.Mallocs - before.Mallocs; mallocs > 100 {
+        t.Fatalf("copying the shared nodes made %d heap allocations", mallocs)
+    }
+    owned := make(map[*rbNode]bool)
+    collectNodes(tree.root, owned)
+    for node := range owned {
+        if shared[node] || node.gen != tree.gen {
+            t.Fatalf("node %v of the tree not owned after the update", node.key)
+        }
+    }
+    if !tree.linked() {
+        t.Fatalf("tree owning all of its nodes again is not linked")
+    }
+    checkRbTree(t, tree.root)
+    checkParents(t, tree.root, nil)
+    if key, _ := tree.Successor(newKey(IntKey(1500))); key.ComparedTo(newKey(IntKey(1501))) != KeysAreEqual {
+        t.Fatalf("Successor(1500) = %v", key)
+    }
+
+    // the owned nodes are changed in place without copying again
+    root := tree.root
+    tree.Insert(newKey(IntKey(1500)), 0)
+    if tree.root != root {
+        t.Fatalf("owned root copied again")
+    }
+    for i := 0; i < 3000; i++ {
+        if value, ok := snapshot.Get(newKey(IntKey(i))); !ok || value != i {
+            t.Fatalf("snapshot Get(%d) = %v, %v", i, value, ok)
+        }
+    }
+}
+
+func benchmarkChurn(b *testing.B, allocation NodeAllocation) {
+    keys := make([]IntKey, 100000)
+    for i := range keys {
+        keys[i] = IntKey(i)
+    }
+
+    b.ReportAllocs()
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        tree := NewRbTreeWithAllocation(allocation)
+        for i := range keys {
+            tree.Insert(&keys[i], i)
+        }
+        for round := 0; round < 4; round++ {
+            for i := round; i < len(keys); i += 4 {
+                tree.Delete(&keys[i])
+            }
+            for i := round; i < len(keys); i += 4 {
+                tree.Insert(&keys[i], i)
+            }
+        }
+    }
+}
+
+func BenchmarkChurnHeap(b *testing.B) {
+    benchmarkChurn(b, HeapAllocation)
+}
+
+func BenchmarkChurnArena(b *testing.B) {
+    benchmarkChurn(b, ArenaAllocation)
+}
@@ -0,0 +1,152 @@
+package rbt
+
+import (
+    "iter"
+    "reflect"
+)
+
+// NewRbTreeFromSorted creates a new RbTree from the keys sorted in ascending order
+// and their values in linear time and returns its address.
+// The values can be nil, otherwise it should have the same length with the keys.
+func NewRbTreeFromSorted(keys []RbKey, values []interface{}) (*RbTree, error) {
+    if values != nil && len(values) != len(keys) {
+        return nil, ErrArgumentLengthMismatch
+    }
+
+    tree := NewRbTree()
+    err := tree.BuildFromSorted(func(yield func(RbKey, interface{}) bool) {
+        for i, key := range keys {
+            var value interface{}
+            if values != nil {
+                value = values[i]
+            }
+            if !yield(key, value) {
+                return
+            }
+        }
+    })
+    if err != nil {
+        return nil, err
+    }
+    return tree, nil
+}
+
+// BuildFromSorted replaces the items of the tree with the items of the sequence
+// sorted in ascending order by key in linear time.
+// The values of the duplicate keys are merged with the insert event of the tree,
+// if the tree has no insert event the duplicate keys are rejected with ErrDuplicateKey.
+// The keys of a type other than the declared key type of the tree or the type of the first key
+// are rejected with ErrKeyTypeMismatch. The tree is not changed if an error is returned.
+func (tree *RbTree) BuildFromSorted(seq iter.Seq2[RbKey, interface{}]) error {
+    if seq == nil {
+        return ArgumentNilError("seq")
+    }
+
+    var err error
+    var keys []RbKey
+    var values []interface{}
+    keyType := tree.keyType
+
+    seq(func(key RbKey, value interface{}) bool {
+        if key == nil {
+            err = ArgumentNilError("key")
+            return false
+        }
+        if keyType == nil {
+            keyType = reflect.TypeOf(key)
+        } else if !keyTypesMatch(reflect.TypeOf(key), keyType) {
+            err = ErrKeyTypeMismatch
+            return false
+        }
+
+        if last := len(keys) - 1; last >= 0 {
+            switch tree.compareKeys(key, keys[last]) {
+            case KeyIsLess:
+                err = ErrKeysNotSorted
+                return false
+            case KeysAreEqual:
+                if tree.onInsert == nil {
+                    err = ErrDuplicateKey
+                    return false
+                }
+                values[last] = tree.onInsert(key, values[last], value)
+                return true
+            }
+        }
+
+        keys = append(keys, key)
+        values = append(values, value)
+        return true
+    })
+    if err != nil {
+        return err
+    }
+
+    // the new nodes are not shared with any other tree, so the tree maintains its parent links again
+    tree.version++
+    tree.gen = 0
+    tree.shared = 0
+    tree.root = buildSorted(keys, values, sortedBlackHeight(len(keys)), tree.gen)
+    tree.count = len(keys)
+    tree.unlinked = false
+    return nil
+}
+
+// sortedBlackHeight returns the black height of the tree built from count sorted keys,
+// which is the largest height that a perfectly balanced all black tree fits in count keys
+func sortedBlackHeight(count int) int {
+    height := 0
+    for full := 1; full <= count; full = full * 2 + 1 {
+        height++
+    }
+    return height
+}
+
+// buildSorted builds a left-leaning red-black tree with the given black height from the sorted keys.
+// A tree with black height h can hold between 2^h-1 (all 2-nodes) and 3^h-1 (all 3-nodes) keys,
+// so the root becomes a 2-node while the keys fit in two children, otherwise a 3-node.
+func buildSorted(keys []RbKey, values []interface{}, blackHeight int, gen uint32) *rbNode {
+    count := len(keys)
+    if count == 0 {
+        return nil
+    }
+
+    capacity := 1
+    for i := 1; i < blackHeight && capacity <= count; i++ {
+        capacity *= 3
+    }
+    childCapacity := capacity - 1
+
+    if count - 1 <= 2 * childCapacity {
+        mid := count / 2
+
+        node := newRbNode(keys[mid], values[mid])
+        node.color = black
+        node.gen = gen
+        node.size = count
+        node.left = buildSorted(keys[:mid], values[:mid], blackHeight - 1, gen)
+        node.right = buildSorted(keys[mid+1:], values[mid+1:], blackHeight - 1, gen)
+        linkChildren(node)
+        return node
+    }
+
+    rest := count - 2
+    lo := (rest + 2) / 3
+    hi := lo + 1 + (rest + 1) / 3
+
+    redNode := newRbNode(keys[lo], values[lo])
+    redNode.gen = gen
+    redNode.size = hi
+    redNode.left = buildSorted(keys[:lo], values[:lo], blackHeight - 1, gen)
+    redNode.right = buildSorted(keys[lo+1:hi], values[lo+1:hi], blackHeight - 1, gen)
+    linkChildren(redNode)
+
+    node := newRbNode(keys[hi], values[hi])
+    node.color = black
+    node.gen = gen
+    node.size = count
+    node.left = redNode
+    node.right = buildSorted(keys[hi+1:], values[hi+1:], blackHeight - 1, gen)
+    linkChildren(node)
+    return node
+}
@@ -3,14 +3,12 @@ package rbt
 // ByteKey is the byte key for RbKey
 type ByteKey byte
 
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (bkey *ByteKey) ComparedTo(key RbKey) KeyComparison {
-    switch {
-    case *bkey > *key.(*ByteKey):
-        return KeyIsGreater
-    case *bkey < *key.(*ByteKey):
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*ByteKey); ok {
+        return compareOrdered(*bkey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(bkey)
+    return compareNumericKey(a, key)
+}
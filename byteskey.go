@@ -0,0 +1,13 @@
+package rbt
+
+import (
+    "bytes"
+)
+
+// BytesKey is the byte slice key for RbKey compared lexicographically
+type BytesKey []byte
+
+// ComparedTo compares the given RbKey with its self
+func (bkey *BytesKey) ComparedTo(key RbKey) KeyComparison {
+    return KeyComparison(bytes.Compare(*bkey, *key.(*BytesKey)))
+}
@@ -0,0 +1,487 @@
+package rbt
+
+import (
+    "encoding/binary"
+    "math"
+    "reflect"
+    "sync"
+)
+
+// KeyCodec interface used for encoding and decoding the keys of a key type
+type KeyCodec interface {
+    // EncodeKey appends the encoded key to the buffer and returns the extended buffer
+    EncodeKey(buf []byte, key RbKey) ([]byte, error)
+    // DecodeKey decodes the key from the data
+    DecodeKey(data []byte) (RbKey, error)
+}
+
+// ValueCodec interface used for encoding and decoding the values of the tree
+type ValueCodec interface {
+    // EncodeValue appends the encoded value to the buffer and returns the extended buffer
+    EncodeValue(buf []byte, value interface{}) ([]byte, error)
+    // DecodeValue decodes the value from the data
+    DecodeValue(data []byte) (interface{}, error)
+}
+
+// MinUserKeyTag is the smallest tag can be registered for a key type,
+// the smaller tags are reserved for the built-in key types
+const MinUserKeyTag = 256
+
+// Tags of the built-in key types
+const (
+    nilKeyTag uint32 = iota + 1
+    boolKeyTag
+    byteKeyTag
+    intKeyTag
+    int8KeyTag
+    int16KeyTag
+    int32KeyTag
+    int64KeyTag
+    uintKeyTag
+    uint8KeyTag
+    uint16KeyTag
+    uint32KeyTag
+    uint64KeyTag
+    float32KeyTag
+    float64KeyTag
+    stringKeyTag
+    compositeKeyTag
+    descendingKeyTag
+    bytesKeyTag
+)
+
+// keyCodecEntry structure holds a registered key codec with its tag
+type keyCodecEntry struct {
+    tag uint32
+    codec KeyCodec
+}
+
+// keyCodecs holds the registered key codecs by their key types and tags
+var keyCodecs = struct {
+    sync.RWMutex
+    byType map[reflect.Type]keyCodecEntry
+    byTag map[uint32]KeyCodec
+}{
+    byType: make(map[reflect.Type]keyCodecEntry),
+    byTag: make(map[uint32]KeyCodec),
+}
+
+func init() {
+    registerKeyCodec(nilKeyTag, &NilKey{}, &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return buf
+        },
+        decode: func(data []byte) (RbKey, error) {
+            if len(data) != 0 {
+                return nil, ErrInvalidFormat
+            }
+            return &NilKey{}, nil
+        },
+    })
+    registerKeyCodec(boolKeyTag, new(BoolKey), &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            if *key.(*BoolKey) {
+                return append(buf, 1)
+            }
+            return append(buf, 0)
+        },
+        decode: func(data []byte) (RbKey, error) {
+            if len(data) != 1 || data[0] > 1 {
+                return nil, ErrInvalidFormat
+            }
+            key := BoolKey(data[0] == 1)
+            return &key, nil
+        },
+    })
+    registerKeyCodec(stringKeyTag, new(StringKey), &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return append(buf, *key.(*StringKey)...)
+        },
+        decode: func(data []byte) (RbKey, error) {
+            key := StringKey(data)
+            return &key, nil
+        },
+    })
+    registerKeyCodec(bytesKeyTag, new(BytesKey), &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return append(buf, *key.(*BytesKey)...)
+        },
+        decode: func(data []byte) (RbKey, error) {
+            key := BytesKey(append([]byte{}, data...))
+            return &key, nil
+        },
+    })
+    registerKeyCodec(float32KeyTag, new(Float32Key), &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(*key.(*Float32Key))))
+        },
+        decode: func(data []byte) (RbKey, error) {
+            if len(data) != 4 {
+                return nil, ErrInvalidFormat
+            }
+            key := Float32Key(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+            return &key, nil
+        },
+    })
+    registerKeyCodec(float64KeyTag, new(Float64Key), &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return binary.LittleEndian.AppendUint64(buf, math.Float64bits(float64(*key.(*Float64Key))))
+        },
+        decode: func(data []byte) (RbKey, error) {
+            if len(data) != 8 {
+                return nil, ErrInvalidFormat
+            }
+            key := Float64Key(math.Float64frombits(binary.LittleEndian.Uint64(data)))
+            return &key, nil
+        },
+    })
+
+    registerKeyCodec(intKeyTag, new(IntKey), signedKeyCodec[IntKey, *IntKey]())
+    registerKeyCodec(int8KeyTag, new(Int8Key), signedKeyCodec[Int8Key, *Int8Key]())
+    registerKeyCodec(int16KeyTag, new(Int16Key), signedKeyCodec[Int16Key, *Int16Key]())
+    registerKeyCodec(int32KeyTag, new(Int32Key), signedKeyCodec[Int32Key, *Int32Key]())
+    registerKeyCodec(int64KeyTag, new(Int64Key), signedKeyCodec[Int64Key, *Int64Key]())
+
+    registerKeyCodec(byteKeyTag, new(ByteKey), unsignedKeyCodec[ByteKey, *ByteKey]())
+    registerKeyCodec(uintKeyTag, new(UintKey), unsignedKeyCodec[UintKey, *UintKey]())
+    registerKeyCodec(uint8KeyTag, new(Uint8Key), unsignedKeyCodec[Uint8Key, *Uint8Key]())
+    registerKeyCodec(uint16KeyTag, new(Uint16Key), unsignedKeyCodec[Uint16Key, *Uint16Key]())
+    registerKeyCodec(uint32KeyTag, new(Uint32Key), unsignedKeyCodec[Uint32Key, *Uint32Key]())
+    registerKeyCodec(uint64KeyTag, new(Uint64Key), unsignedKeyCodec[Uint64Key, *Uint64Key]())
+
+    registerKeyCodec(compositeKeyTag, new(CompositeKey), compositeKeyCodec{})
+    registerKeyCodec(descendingKeyTag, new(DescendingKey), descendingKeyCodec{})
+}
+
+// RegisterKeyCodec registers the codec for the type of the sample key with the given tag.
+// The tag is stored with each encoded key, so it should not be changed once data is persisted.
+// Returns ErrKeyCodecExists if the tag is less than MinUserKeyTag or
+// the tag or the key type is already registered.
+func RegisterKeyCodec(tag uint32, sample RbKey, codec KeyCodec) error {
+    if sample == nil {
+        return ArgumentNilError("sample")
+    }
+    if codec == nil {
+        return ArgumentNilError("codec")
+    }
+    if tag < MinUserKeyTag {
+        return ErrKeyCodecExists
+    }
+    return registerKeyCodec(tag, sample, codec)
+}
+
+// registerKeyCodec registers the codec for the type of the sample key with the given tag
+func registerKeyCodec(tag uint32, sample RbKey, codec KeyCodec) error {
+    keyType := reflect.TypeOf(sample)
+
+    keyCodecs.Lock()
+    defer keyCodecs.Unlock()
+
+    if _, ok := keyCodecs.byTag[tag]; ok {
+        return ErrKeyCodecExists
+    }
+    if _, ok := keyCodecs.byType[keyType]; ok {
+        return ErrKeyCodecExists
+    }
+    keyCodecs.byType[keyType] = keyCodecEntry{tag: tag, codec: codec}
+    keyCodecs.byTag[tag] = codec
+    return nil
+}
+
+// keyCodecOf returns the registered codec of the key type with its tag
+func keyCodecOf(key RbKey) (uint32, KeyCodec, error) {
+    keyCodecs.RLock()
+    entry, ok := keyCodecs.byType[reflect.TypeOf(key)]
+    keyCodecs.RUnlock()
+
+    if !ok {
+        return 0, nil, ErrKeyCodecNotFound
+    }
+    return entry.tag, entry.codec, nil
+}
+
+// keyCodecByTag returns the codec registered with the tag
+func keyCodecByTag(tag uint32) (KeyCodec, error) {
+    keyCodecs.RLock()
+    codec, ok := keyCodecs.byTag[tag]
+    keyCodecs.RUnlock()
+
+    if !ok {
+        return nil, ErrKeyCodecNotFound
+    }
+    return codec, nil
+}
+
+// keyCodecFuncs structure implements KeyCodec for the built-in key types
+type keyCodecFuncs struct {
+    encode func(buf []byte, key RbKey) []byte
+    decode func(data []byte) (RbKey, error)
+}
+
+// EncodeKey appends the encoded key to the buffer and returns the extended buffer
+func (codec *keyCodecFuncs) EncodeKey(buf []byte, key RbKey) ([]byte, error) {
+    return codec.encode(buf, key), nil
+}
+
+// DecodeKey decodes the key from the data
+func (codec *keyCodecFuncs) DecodeKey(data []byte) (RbKey, error) {
+    return codec.decode(data)
+}
+
+// signedKeyCodec creates the varint codec of a signed integer key type
+func signedKeyCodec[T ~int | ~int8 | ~int16 | ~int32 | ~int64, P interface { *T; RbKey }]() KeyCodec {
+    return &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return binary.AppendVarint(buf, int64(*key.(P)))
+        },
+        decode: func(data []byte) (RbKey, error) {
+            v, n := binary.Varint(data)
+            if n <= 0 || n != len(data) || int64(T(v)) != v {
+                return nil, ErrInvalidFormat
+            }
+            key := T(v)
+            return P(&key), nil
+        },
+    }
+}
+
+// unsignedKeyCodec creates the uvarint codec of an unsigned integer key type
+func unsignedKeyCodec[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64, P interface { *T; RbKey }]() KeyCodec {
+    return &keyCodecFuncs{
+        encode: func(buf []byte, key RbKey) []byte {
+            return binary.AppendUvarint(buf, uint64(*key.(P)))
+        },
+        decode: func(data []byte) (RbKey, error) {
+            v, n := binary.Uvarint(data)
+            if n <= 0 || n != len(data) || uint64(T(v)) != v {
+                return nil, ErrInvalidFormat
+            }
+            key := T(v)
+            return P(&key), nil
+        },
+    }
+}
+
+// appendTaggedKey appends the key to the buffer as its uvarint tag, the uvarint length and the bytes of the key
+func appendTaggedKey(buf []byte, key RbKey) ([]byte, error) {
+    tag, codec, err := keyCodecOf(key)
+    if err != nil {
+        return nil, err
+    }
+    data, err := codec.EncodeKey(nil, key)
+    if err != nil {
+        return nil, err
+    }
+    buf = binary.AppendUvarint(buf, uint64(tag))
+    buf = binary.AppendUvarint(buf, uint64(len(data)))
+    return append(buf, data...), nil
+}
+
+// readTaggedKey decodes a key appended with appendTaggedKey and returns it with the rest of the data
+func readTaggedKey(data []byte) (RbKey, []byte, error) {
+    tag, n := binary.Uvarint(data)
+    if n <= 0 || tag > uint64(^uint32(0)) {
+        return nil, nil, ErrInvalidFormat
+    }
+    codec, err := keyCodecByTag(uint32(tag))
+    if err != nil {
+        return nil, nil, err
+    }
+
+    data = data[n:]
+    length, n := binary.Uvarint(data)
+    if n <= 0 || length > uint64(len(data) - n) {
+        return nil, nil, ErrInvalidFormat
+    }
+    data = data[n:]
+
+    key, err := codec.DecodeKey(data[:length])
+    if err != nil {
+        return nil, nil, err
+    }
+    return key, data[length:], nil
+}
+
+// compositeKeyCodec structure implements KeyCodec for CompositeKey encoding its components with their codecs
+type compositeKeyCodec struct {}
+
+// EncodeKey appends the encoded key to the buffer and returns the extended buffer
+func (compositeKeyCodec) EncodeKey(buf []byte, key RbKey) ([]byte, error) {
+    var err error
+    for _, component := range *key.(*CompositeKey) {
+        if buf, err = appendTaggedKey(buf, component); err != nil {
+            return nil, err
+        }
+    }
+    return buf, nil
+}
+
+// DecodeKey decodes the key from the data
+func (compositeKeyCodec) DecodeKey(data []byte) (RbKey, error) {
+    var key CompositeKey
+    for len(data) > 0 {
+        component, rest, err := readTaggedKey(data)
+        if err != nil {
+            return nil, err
+        }
+        key = append(key, component)
+        data = rest
+    }
+    return &key, nil
+}
+
+// descendingKeyCodec structure implements KeyCodec for DescendingKey encoding the wrapped key with its codec
+type descendingKeyCodec struct {}
+
+// EncodeKey appends the encoded key to the buffer and returns the extended buffer
+func (descendingKeyCodec) EncodeKey(buf []byte, key RbKey) ([]byte, error) {
+    return appendTaggedKey(buf, key.(*DescendingKey).Key)
+}
+
+// DecodeKey decodes the key from the data
+func (descendingKeyCodec) DecodeKey(data []byte) (RbKey, error) {
+    key, rest, err := readTaggedKey(data)
+    if err != nil {
+        return nil, err
+    }
+    if len(rest) != 0 {
+        return nil, ErrInvalidFormat
+    }
+    return Desc(key), nil
+}
+
+// Tags of the value types supported by DefaultValueCodec
+const (
+    nilValueTag byte = iota
+    boolValueTag
+    intValueTag
+    int8ValueTag
+    int16ValueTag
+    int32ValueTag
+    int64ValueTag
+    uintValueTag
+    uint8ValueTag
+    uint16ValueTag
+    uint32ValueTag
+    uint64ValueTag
+    float32ValueTag
+    float64ValueTag
+    stringValueTag
+    bytesValueTag
+)
+
+// DefaultValueCodec is the value codec used if the tree has no value codec.
+// It supports nil, bool, string, []byte and the built-in integer and floating point types.
+var DefaultValueCodec ValueCodec = basicValueCodec{}
+
+// basicValueCodec structure implements the DefaultValueCodec
+type basicValueCodec struct {}
+
+// EncodeValue appends the encoded value to the buffer and returns the extended buffer,
+// returns ErrValueTypeNotSupported if the value type is not supported
+func (basicValueCodec) EncodeValue(buf []byte, value interface{}) ([]byte, error) {
+    switch v := value.(type) {
+    case nil:
+        return append(buf, nilValueTag), nil
+    case bool:
+        if v {
+            return append(buf, boolValueTag, 1), nil
+        }
+        return append(buf, boolValueTag, 0), nil
+    case int:
+        return binary.AppendVarint(append(buf, intValueTag), int64(v)), nil
+    case int8:
+        return binary.AppendVarint(append(buf, int8ValueTag), int64(v)), nil
+    case int16:
+        return binary.AppendVarint(append(buf, int16ValueTag), int64(v)), nil
+    case int32:
+        return binary.AppendVarint(append(buf, int32ValueTag), int64(v)), nil
+    case int64:
+        return binary.AppendVarint(append(buf, int64ValueTag), v), nil
+    case uint:
+        return binary.AppendUvarint(append(buf, uintValueTag), uint64(v)), nil
+    case uint8:
+        return binary.AppendUvarint(append(buf, uint8ValueTag), uint64(v)), nil
+    case uint16:
+        return binary.AppendUvarint(append(buf, uint16ValueTag), uint64(v)), nil
+    case uint32:
+        return binary.AppendUvarint(append(buf, uint32ValueTag), uint64(v)), nil
+    case uint64:
+        return binary.AppendUvarint(append(buf, uint64ValueTag), v), nil
+    case float32:
+        return binary.LittleEndian.AppendUint32(append(buf, float32ValueTag), math.Float32bits(v)), nil
+    case float64:
+        return binary.LittleEndian.AppendUint64(append(buf, float64ValueTag), math.Float64bits(v)), nil
+    case string:
+        return append(append(buf, stringValueTag), v...), nil
+    case []byte:
+        return append(append(buf, bytesValueTag), v...), nil
+    }
+    return nil, ErrValueTypeNotSupported
+}
+
+// DecodeValue decodes the value from the data
+func (basicValueCodec) DecodeValue(data []byte) (interface{}, error) {
+    if len(data) == 0 {
+        return nil, ErrInvalidFormat
+    }
+
+    tag, data := data[0], data[1:]
+    switch tag {
+    case nilValueTag:
+        if len(data) == 0 {
+            return nil, nil
+        }
+    case boolValueTag:
+        if len(data) == 1 && data[0] <= 1 {
+            return data[0] == 1, nil
+        }
+    case intValueTag, int8ValueTag, int16ValueTag, int32ValueTag, int64ValueTag:
+        v, n := binary.Varint(data)
+        if n <= 0 || n != len(data) {
+            break
+        }
+        switch {
+        case tag == intValueTag && int64(int(v)) == v:
+            return int(v), nil
+        case tag == int8ValueTag && int64(int8(v)) == v:
+            return int8(v), nil
+        case tag == int16ValueTag && int64(int16(v)) == v:
+            return int16(v), nil
+        case tag == int32ValueTag && int64(int32(v)) == v:
+            return int32(v), nil
+        case tag == int64ValueTag:
+            return v, nil
+        }
+    case uintValueTag, uint8ValueTag, uint16ValueTag, uint32ValueTag, uint64ValueTag:
+        v, n := binary.Uvarint(data)
+        if n <= 0 || n != len(data) {
+            break
+        }
+        switch {
+        case tag == uintValueTag && uint64(uint(v)) == v:
+            return uint(v), nil
+        case tag == uint8ValueTag && uint64(uint8(v)) == v:
+            return uint8(v), nil
+        case tag == uint16ValueTag && uint64(uint16(v)) == v:
+            return uint16(v), nil
+        case tag == uint32ValueTag && uint64(uint32(v)) == v:
+            return uint32(v), nil
+        case tag == uint64ValueTag:
+            return v, nil
+        }
+    case float32ValueTag:
+        if len(data) == 4 {
+            return math.Float32frombits(binary.LittleEndian.Uint32(data)), nil
+        }
+    case float64ValueTag:
+        if len(data) == 8 {
+            return math.Float64frombits(binary.LittleEndian.Uint64(data)), nil
+        }
+    case stringValueTag:
+        return string(data), nil
+    case bytesValueTag:
+        return append([]byte{}, data...), nil
+    }
+    return nil, ErrInvalidFormat
+}
@@ -0,0 +1,677 @@
+package rbt
+
+import (
+    "cmp"
+    "iter"
+)
+
+const (
+    // compactRed is the bit of the right index of a compact node set if the node is red
+    compactRed = uint32(1) << 31
+    // compactIndexMask masks the node index in the right index of a compact node
+    compactIndexMask = compactRed - 1
+)
+
+// compactMaxIndex is the largest node index of a CompactTree
+var compactMaxIndex = compactIndexMask
+
+// CompactTree structure is the variant of Tree storing its nodes in a slice addressed by uint32 indices.
+// The nodes hold no pointers, so the tree is not scanned by the garbage collector
+// if the key and value types hold no pointers. The tree holds up to 2^31 - 1 items.
+//
+// CompactTree offers the lookups, modifications, order statistics, sequences, callback iterators
+// and cursors of RbTree with typed keys and values, NewCompactRbTree creates one with RbKey keys.
+// The nodes have no parent links, so Successor, Predecessor and the cursor steps search from the root.
+// The snapshots, set operations, split and join of RbTree share nodes between trees, so they are
+// not provided by the tree owning all of its nodes in a single storage.
+type CompactTree[K any, V any] struct {
+    nodes []compactNode[K, V]
+    root uint32
+    free uint32
+    count int
+    version uint32
+    compare func(a, b K) KeyComparison
+}
+
+// compactNode structure used for storing typed key and value pairs in a CompactTree,
+// the index 0 is used as nil and the color is packed into the highest bit of the right index
+type compactNode[K any, V any] struct {
+    key K
+    value V
+    left uint32
+    right uint32
+    size uint32
+}
+
+// NewCompactTree creates a new CompactTree for the ordered key type K and returns its address
+func NewCompactTree[K cmp.Ordered, V any]() *CompactTree[K, V] {
+    return &CompactTree[K, V]{
+        compare: compareOrdered[K],
+    }
+}
+
+// NewCompactRbTree creates a new CompactTree for the RbKey keys ordered by their ComparedTo method
+// and returns its address. The keys and values are interfaces, so the tree is scanned by the garbage collector.
+func NewCompactRbTree() *CompactTree[RbKey, interface{}] {
+    return &CompactTree[RbKey, interface{}]{
+        compare: func(a, b RbKey) KeyComparison {
+            return a.ComparedTo(b)
+        },
+    }
+}
+
+// NewCompactTreeWithComparator creates a new CompactTree ordering its keys with the given compare function
+// and returns its address
+func NewCompactTreeWithComparator[K any, V any](compare func(a, b K) KeyComparison) *CompactTree[K, V] {
+    if compare == nil {
+        return nil
+    }
+    return &CompactTree[K, V]{
+        compare: compare,
+    }
+}
+
+// Grow grows the node storage of the tree to hold n more items without reallocation
+func (tree *CompactTree[K, V]) Grow(n int) {
+    if n > 0 && cap(tree.nodes) - len(tree.nodes) < n + 1 {
+        nodes := make([]compactNode[K, V], len(tree.nodes), len(tree.nodes) + n + 1)
+        copy(nodes, tree.nodes)
+        tree.nodes = nodes
+    }
+}
+
+// newNode creates a new red node from the released nodes if exists, otherwise at the end of the storage
+func (tree *CompactTree[K, V]) newNode(key K, value V) uint32 {
+    node := compactNode[K, V]{
+        key: key,
+        value: value,
+        right: compactRed,
+        size: 1,
+    }
+
+    if i := tree.free; i != 0 {
+        tree.free = tree.nodes[i].left
+        tree.nodes[i] = node
+        return i
+    }
+
+    if len(tree.nodes) == 0 {
+        tree.nodes = append(tree.nodes, compactNode[K, V]{})
+    }
+    if tree.full() {
+        panic(ErrCapacityExceeded)
+    }
+    tree.nodes = append(tree.nodes, node)
+    return uint32(len(tree.nodes) - 1)
+}
+
+// full returns 'true' if no more node can be created in the storage of the tree
+func (tree *CompactTree[K, V]) full() bool {
+    return tree.free == 0 && uint64(len(tree.nodes)) > uint64(compactMaxIndex)
+}
+
+// releaseNode clears the node and adds it to the released nodes
+func (tree *CompactTree[K, V]) releaseNode(i uint32) {
+    tree.nodes[i] = compactNode[K, V]{
+        left: tree.free,
+    }
+    tree.free = i
+}
+
+// left returns the index of the left child of the node
+func (tree *CompactTree[K, V]) left(i uint32) uint32 {
+    return tree.nodes[i].left
+}
+
+// right returns the index of the right child of the node
+func (tree *CompactTree[K, V]) right(i uint32) uint32 {
+    return tree.nodes[i].right & compactIndexMask
+}
+
+// setLeft sets the left child of the node
+func (tree *CompactTree[K, V]) setLeft(i, child uint32) {
+    tree.nodes[i].left = child
+}
+
+// setRight sets the right child of the node keeping its color
+func (tree *CompactTree[K, V]) setRight(i, child uint32) {
+    node := &tree.nodes[i]
+    node.right = node.right & compactRed | child
+}
+
+// isRed checks if node exists and its color is red
+func (tree *CompactTree[K, V]) isRed(i uint32) bool {
+    return i != 0 && tree.nodes[i].right & compactRed != 0
+}
+
+// isBlack checks if node exists and its color is black
+func (tree *CompactTree[K, V]) isBlack(i uint32) bool {
+    return i != 0 && tree.nodes[i].right & compactRed == 0
+}
+
+// setRed sets the color of the node to red if red is 'true', otherwise to black
+func (tree *CompactTree[K, V]) setRed(i uint32, red bool) {
+    node := &tree.nodes[i]
+    if red {
+        node.right |= compactRed
+    } else {
+        node.right &= compactIndexMask
+    }
+}
+
+// min finds the smallest node key including the node
+func (tree *CompactTree[K, V]) min(i uint32) uint32 {
+    if i != 0 {
+        for tree.left(i) != 0 {
+            i = tree.left(i)
+        }
+    }
+    return i
+}
+
+// max finds the greatest node key including the node
+func (tree *CompactTree[K, V]) max(i uint32) uint32 {
+    if i != 0 {
+        for tree.right(i) != 0 {
+            i = tree.right(i)
+        }
+    }
+    return i
+}
+
+// size returns the count of the nodes in the subtree
+func (tree *CompactTree[K, V]) size(i uint32) int {
+    if i == 0 {
+        return 0
+    }
+    return int(tree.nodes[i].size)
+}
+
+// updateSize recalculates the size of the node from its children
+func (tree *CompactTree[K, V]) updateSize(i uint32) {
+    tree.nodes[i].size = uint32(1 + tree.size(tree.left(i)) + tree.size(tree.right(i)))
+}
+
+// colorFlip switchs the color of the node and its children from red to black or black to red
+func (tree *CompactTree[K, V]) colorFlip(i uint32) {
+    tree.nodes[i].right ^= compactRed
+    tree.nodes[tree.left(i)].right ^= compactRed
+    tree.nodes[tree.right(i)].right ^= compactRed
+}
+
+// rotateLeft makes a right-leaning link lean to the left
+func (tree *CompactTree[K, V]) rotateLeft(i uint32) uint32 {
+    child := tree.right(i)
+    tree.setRight(i, tree.left(child))
+    tree.setLeft(child, i)
+    tree.setRed(child, tree.isRed(i))
+    tree.setRed(i, true)
+    tree.nodes[child].size = tree.nodes[i].size
+    tree.updateSize(i)
+
+    return child
+}
+
+// rotateRight makes a left-leaning link lean to the right
+func (tree *CompactTree[K, V]) rotateRight(i uint32) uint32 {
+    child := tree.left(i)
+    tree.setLeft(i, tree.right(child))
+    tree.setRight(child, i)
+    tree.setRed(child, tree.isRed(i))
+    tree.setRed(i, true)
+    tree.nodes[child].size = tree.nodes[i].size
+    tree.updateSize(i)
+
+    return child
+}
+
+// moveRedLeft makes node.left or one of its children red,
+// assuming that node is red and both children are black.
+func (tree *CompactTree[K, V]) moveRedLeft(i uint32) uint32 {
+    tree.colorFlip(i)
+    if tree.isRed(tree.left(tree.right(i))) {
+        tree.setRight(i, tree.rotateRight(tree.right(i)))
+        i = tree.rotateLeft(i)
+        tree.colorFlip(i)
+    }
+    return i
+}
+
+// moveRedRight makes node.right or one of its children red,
+// assuming that node is red and both children are black.
+func (tree *CompactTree[K, V]) moveRedRight(i uint32) uint32 {
+    tree.colorFlip(i)
+    if tree.isRed(tree.left(tree.left(i))) {
+        i = tree.rotateRight(i)
+        tree.colorFlip(i)
+    }
+    return i
+}
+
+// balance restores red-black tree invariant
+func (tree *CompactTree[K, V]) balance(i uint32) uint32 {
+    if tree.isRed(tree.right(i)) {
+        i = tree.rotateLeft(i)
+    }
+    if tree.isRed(tree.left(i)) && tree.isRed(tree.left(tree.left(i))) {
+        i = tree.rotateRight(i)
+    }
+    if tree.isRed(tree.left(i)) && tree.isRed(tree.right(i)) {
+        tree.colorFlip(i)
+    }
+    tree.updateSize(i)
+    return i
+}
+
+// deleteMin removes the smallest key and associated value from the subtree,
+// the removed node is not released
+func (tree *CompactTree[K, V]) deleteMin(i uint32) uint32 {
+    if tree.left(i) == 0 {
+        return 0
+    }
+    if tree.isBlack(tree.left(i)) && !tree.isRed(tree.left(tree.left(i))) {
+        i = tree.moveRedLeft(i)
+    }
+    tree.setLeft(i, tree.deleteMin(tree.left(i)))
+    return tree.balance(i)
+}
+
+// Count returns if count of the nodes stored.
+func (tree *CompactTree[K, V]) Count() int {
+    return tree.count
+}
+
+// IsEmpty returns if the tree has any node.
+func (tree *CompactTree[K, V]) IsEmpty() bool {
+    return tree.root == 0
+}
+
+// Min returns the smallest key in the tree and 'true',
+// otherwise returns 'false' as third return param if the tree is empty
+func (tree *CompactTree[K, V]) Min() (key K, value V, ok bool) {
+    if tree.root != 0 {
+        result := &tree.nodes[tree.min(tree.root)]
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Max returns the largest key in the tree and 'true',
+// otherwise returns 'false' as third return param if the tree is empty
+func (tree *CompactTree[K, V]) Max() (key K, value V, ok bool) {
+    if tree.root != 0 {
+        result := &tree.nodes[tree.max(tree.root)]
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Floor returns the largest key in the tree less than or equal to key
+func (tree *CompactTree[K, V]) Floor(key K) (floorKey K, value V, ok bool) {
+    if i := tree.floor(key); i != 0 {
+        return tree.nodes[i].key, tree.nodes[i].value, true
+    }
+    return
+}
+
+// floor returns the index of the node with the largest key less than or equal to key, otherwise returns 0
+func (tree *CompactTree[K, V]) floor(key K) uint32 {
+    var result uint32
+    for i := tree.root; i != 0; {
+        switch tree.compare(key, tree.nodes[i].key) {
+        case KeysAreEqual:
+            return i
+        case KeyIsLess:
+            i = tree.left(i)
+        default:
+            result = i
+            i = tree.right(i)
+        }
+    }
+    return result
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to key
+func (tree *CompactTree[K, V]) Ceiling(key K) (ceilingKey K, value V, ok bool) {
+    if i := tree.ceiling(key); i != 0 {
+        return tree.nodes[i].key, tree.nodes[i].value, true
+    }
+    return
+}
+
+// ceiling returns the index of the node with the smallest key greater than or equal to key, otherwise returns 0
+func (tree *CompactTree[K, V]) ceiling(key K) uint32 {
+    var result uint32
+    for i := tree.root; i != 0; {
+        switch tree.compare(key, tree.nodes[i].key) {
+        case KeysAreEqual:
+            return i
+        case KeyIsGreater:
+            i = tree.right(i)
+        default:
+            result = i
+            i = tree.left(i)
+        }
+    }
+    return result
+}
+
+// Successor returns the smallest key in the tree strictly greater than key and 'true',
+// otherwise returns 'false' as third return param if there is no such key
+func (tree *CompactTree[K, V]) Successor(key K) (successorKey K, value V, ok bool) {
+    if i := tree.higher(key); i != 0 {
+        return tree.nodes[i].key, tree.nodes[i].value, true
+    }
+    return
+}
+
+// Predecessor returns the largest key in the tree strictly less than key and 'true',
+// otherwise returns 'false' as third return param if there is no such key
+func (tree *CompactTree[K, V]) Predecessor(key K) (predecessorKey K, value V, ok bool) {
+    if i := tree.lower(key); i != 0 {
+        return tree.nodes[i].key, tree.nodes[i].value, true
+    }
+    return
+}
+
+// higher returns the index of the node with the smallest key greater than key, otherwise returns 0
+func (tree *CompactTree[K, V]) higher(key K) uint32 {
+    var result uint32
+    for i := tree.root; i != 0; {
+        if tree.compare(key, tree.nodes[i].key) == KeyIsLess {
+            result = i
+            i = tree.left(i)
+        } else {
+            i = tree.right(i)
+        }
+    }
+    return result
+}
+
+// lower returns the index of the node with the largest key less than key, otherwise returns 0
+func (tree *CompactTree[K, V]) lower(key K) uint32 {
+    var result uint32
+    for i := tree.root; i != 0; {
+        if tree.compare(key, tree.nodes[i].key) == KeyIsGreater {
+            result = i
+            i = tree.right(i)
+        } else {
+            i = tree.left(i)
+        }
+    }
+    return result
+}
+
+// Rank returns the count of the keys in the tree strictly less than the given key
+func (tree *CompactTree[K, V]) Rank(key K) int {
+    return tree.rank(key, false)
+}
+
+// Select returns the key and value at the given zero based position in the sorted order of the tree and 'true',
+// otherwise returns 'false' as third return param if the position is out of range
+func (tree *CompactTree[K, V]) Select(index int) (key K, value V, ok bool) {
+    if index < 0 || index >= tree.count {
+        return
+    }
+
+    for i := tree.root; i != 0; {
+        leftSize := tree.size(tree.left(i))
+        switch {
+        case index < leftSize:
+            i = tree.left(i)
+        case index > leftSize:
+            index -= leftSize + 1
+            i = tree.right(i)
+        default:
+            return tree.nodes[i].key, tree.nodes[i].value, true
+        }
+    }
+    return
+}
+
+// CountBetween returns the count of the keys in the tree that are
+// greater or equal to loKey and less or equal to hiKey
+func (tree *CompactTree[K, V]) CountBetween(loKey K, hiKey K) int {
+    if tree.compare(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return tree.rank(hiKey, true) - tree.rank(loKey, false)
+}
+
+// rank returns the count of the keys in the tree less than the given key,
+// the key equal to the given key is also counted if inclusive
+func (tree *CompactTree[K, V]) rank(key K, inclusive bool) int {
+    result := 0
+    for i := tree.root; i != 0; {
+        switch tree.compare(key, tree.nodes[i].key) {
+        case KeyIsLess:
+            i = tree.left(i)
+        case KeyIsGreater:
+            result += tree.size(tree.left(i)) + 1
+            i = tree.right(i)
+        default:
+            result += tree.size(tree.left(i))
+            if inclusive {
+                result++
+            }
+            return result
+        }
+    }
+    return result
+}
+
+// Get returns the stored value if key found and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *CompactTree[K, V]) Get(key K) (value V, ok bool) {
+    if i := tree.find(key); i != 0 {
+        return tree.nodes[i].value, true
+    }
+    return
+}
+
+// find returns the index of the node if key found, otherwise returns 0
+func (tree *CompactTree[K, V]) find(key K) uint32 {
+    for i := tree.root; i != 0; {
+        switch tree.compare(key, tree.nodes[i].key) {
+        case KeyIsLess:
+            i = tree.left(i)
+        case KeyIsGreater:
+            i = tree.right(i)
+        default:
+            return i
+        }
+    }
+    return 0
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *CompactTree[K, V]) Exists(key K) bool {
+    return tree.find(key) != 0
+}
+
+// Insert inserts the given key and value into the tree,
+// panics with ErrCapacityExceeded if the tree is full and the key does not exist, see TryInsert
+func (tree *CompactTree[K, V]) Insert(key K, value V) {
+    tree.version++
+    tree.root = tree.insertNode(tree.root, key, value)
+    tree.setRed(tree.root, false)
+}
+
+// TryInsert inserts the given key and value into the tree,
+// returns ErrCapacityExceeded instead of inserting if the tree is full and the key does not exist
+func (tree *CompactTree[K, V]) TryInsert(key K, value V) error {
+    if tree.full() && tree.find(key) == 0 {
+        return ErrCapacityExceeded
+    }
+    tree.Insert(key, value)
+    return nil
+}
+
+// insertNode adds the given key and value into the node.
+// The storage may be reallocated while adding, so no node is referenced across the recursive call.
+func (tree *CompactTree[K, V]) insertNode(i uint32, key K, value V) uint32 {
+    if i == 0 {
+        tree.count++
+        return tree.newNode(key, value)
+    }
+
+    switch tree.compare(key, tree.nodes[i].key) {
+    case KeyIsLess:
+        child := tree.insertNode(tree.left(i), key, value)
+        tree.setLeft(i, child)
+    case KeyIsGreater:
+        child := tree.insertNode(tree.right(i), key, value)
+        tree.setRight(i, child)
+    default:
+        tree.nodes[i].value = value
+    }
+    return tree.balance(i)
+}
+
+// Delete deletes the given key from the tree
+func (tree *CompactTree[K, V]) Delete(key K) {
+    if tree.find(key) == 0 {
+        return
+    }
+
+    tree.version++
+    tree.root = tree.deleteNode(tree.root, key)
+    if tree.root != 0 {
+        tree.setRed(tree.root, false)
+    }
+    tree.count--
+}
+
+// deleteNode deletes the given key from the node,
+// assuming that the key exists in the subtree
+func (tree *CompactTree[K, V]) deleteNode(i uint32, key K) uint32 {
+    if tree.compare(key, tree.nodes[i].key) == KeyIsLess {
+        if tree.isBlack(tree.left(i)) && !tree.isRed(tree.left(tree.left(i))) {
+            i = tree.moveRedLeft(i)
+        }
+        tree.setLeft(i, tree.deleteNode(tree.left(i), key))
+    } else {
+        if tree.isRed(tree.left(i)) {
+            i = tree.rotateRight(i)
+        }
+
+        if tree.compare(key, tree.nodes[i].key) == KeysAreEqual && tree.right(i) == 0 {
+            tree.releaseNode(i)
+            return 0
+        }
+
+        if tree.isBlack(tree.right(i)) && !tree.isRed(tree.left(tree.right(i))) {
+            i = tree.moveRedRight(i)
+        }
+
+        if tree.compare(key, tree.nodes[i].key) != KeysAreEqual {
+            tree.setRight(i, tree.deleteNode(tree.right(i), key))
+        } else {
+            rm := tree.min(tree.right(i))
+            tree.nodes[i].key = tree.nodes[rm].key
+            tree.nodes[i].value = tree.nodes[rm].value
+            tree.setRight(i, tree.deleteMin(tree.right(i)))
+            tree.releaseNode(rm)
+        }
+    }
+    return tree.balance(i)
+}
+
+// All returns a sequence of all items of the tree in ascending order
+func (tree *CompactTree[K, V]) All() iter.Seq2[K, V] {
+    return tree.seq(nil, nil)
+}
+
+// Backward returns a sequence of all items of the tree in descending order
+func (tree *CompactTree[K, V]) Backward() iter.Seq2[K, V] {
+    return func(yield func(K, V) bool) {
+        version := tree.version
+        var stack []uint32
+        for i := tree.root; i != 0; i = tree.right(i) {
+            stack = append(stack, i)
+        }
+
+        for len(stack) > 0 {
+            i := stack[len(stack) - 1]
+            stack = stack[:len(stack) - 1]
+            if !yield(tree.nodes[i].key, tree.nodes[i].value) {
+                return
+            }
+            if version != tree.version {
+                panic(ErrEnumeratorModified)
+            }
+
+            for j := tree.left(i); j != 0; j = tree.right(j) {
+                stack = append(stack, j)
+            }
+        }
+    }
+}
+
+// Range returns a sequence of the items of the tree in ascending order that the key of the item
+// is greater or equal to loKey and less or equal to hiKey
+func (tree *CompactTree[K, V]) Range(loKey K, hiKey K) iter.Seq2[K, V] {
+    if tree.compare(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return tree.seq(&treeBound[K]{key: loKey, inclusive: true}, &treeBound[K]{key: hiKey, inclusive: true})
+}
+
+// From returns a sequence of the items of the tree in ascending order that the key of the item
+// is greater or equal to the given key
+func (tree *CompactTree[K, V]) From(key K) iter.Seq2[K, V] {
+    return tree.seq(&treeBound[K]{key: key, inclusive: true}, nil)
+}
+
+// Until returns a sequence of the items of the tree in ascending order that the key of the item
+// is less than the given key
+func (tree *CompactTree[K, V]) Until(key K) iter.Seq2[K, V] {
+    return tree.seq(nil, &treeBound[K]{key: key})
+}
+
+// seq returns a sequence of the items of the tree in ascending order within the bounds,
+// a nil bound leaves that side of the range open
+func (tree *CompactTree[K, V]) seq(lo, hi *treeBound[K]) iter.Seq2[K, V] {
+    return func(yield func(K, V) bool) {
+        version := tree.version
+        var stack []uint32
+        for i := tree.root; i != 0; {
+            if lo != nil && !tree.aboveLo(tree.nodes[i].key, lo) {
+                i = tree.right(i)
+            } else {
+                stack = append(stack, i)
+                i = tree.left(i)
+            }
+        }
+
+        for len(stack) > 0 {
+            i := stack[len(stack) - 1]
+            stack = stack[:len(stack) - 1]
+            if hi != nil && !tree.belowHi(tree.nodes[i].key, hi) {
+                return
+            }
+            if !yield(tree.nodes[i].key, tree.nodes[i].value) {
+                return
+            }
+            if version != tree.version {
+                panic(ErrEnumeratorModified)
+            }
+
+            for j := tree.right(i); j != 0; j = tree.left(j) {
+                stack = append(stack, j)
+            }
+        }
+    }
+}
+
+// aboveLo checks if the key is within the lower bound
+func (tree *CompactTree[K, V]) aboveLo(key K, lo *treeBound[K]) bool {
+    cmp := tree.compare(key, lo.key)
+    return cmp == KeyIsGreater || (lo.inclusive && cmp == KeysAreEqual)
+}
+
+// belowHi checks if the key is within the upper bound
+func (tree *CompactTree[K, V]) belowHi(key K, hi *treeBound[K]) bool {
+    cmp := tree.compare(key, hi.key)
+    return cmp == KeyIsLess || (hi.inclusive && cmp == KeysAreEqual)
+}
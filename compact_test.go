@@ -0,0 +1,454 @@
+package rbt
+
+import (
+    "math/rand"
+    "testing"
+    "unsafe"
+)
+
+// checkCompactTree validates the left-leaning red-black invariants of the subtree
+// and returns its black height
+func checkCompactTree[K any, V any](t *testing.T, tree *CompactTree[K, V], i uint32) int {
+    if i == 0 {
+        return 0
+    }
+    left, right := tree.left(i), tree.right(i)
+    if tree.isRed(right) {
+        t.Fatalf("right-leaning red link at %v", tree.nodes[i].key)
+    }
+    if tree.isRed(i) && tree.isRed(left) {
+        t.Fatalf("two red links in a row at %v", tree.nodes[i].key)
+    }
+    if left != 0 && tree.compare(tree.nodes[left].key, tree.nodes[i].key) != KeyIsLess {
+        t.Fatalf("left key %v is not less than %v", tree.nodes[left].key, tree.nodes[i].key)
+    }
+    if right != 0 && tree.compare(tree.nodes[right].key, tree.nodes[i].key) != KeyIsGreater {
+        t.Fatalf("right key %v is not greater than %v", tree.nodes[right].key, tree.nodes[i].key)
+    }
+
+    if size := tree.size(left) + tree.size(right) + 1; tree.size(i) != size {
+        t.Fatalf("size at %v is %d, want %d", tree.nodes[i].key, tree.size(i), size)
+    }
+
+    lh, rh := checkCompactTree(t, tree, left), checkCompactTree(t, tree, right)
+    if lh != rh {
+        t.Fatalf("black height mismatch at %v: %d != %d", tree.nodes[i].key, lh, rh)
+    }
+    if tree.isBlack(i) {
+        lh++
+    }
+    return lh
+}
+
+func TestCompactTree(t *testing.T) {
+    if size := unsafe.Sizeof(compactNode[uint64, uint64]{}); size != 32 {
+        t.Fatalf("compact node of uint64 key and value takes %d bytes, want 32", size)
+    }
+    if size := unsafe.Sizeof(compactNode[uint32, uint32]{}); size != 20 {
+        t.Fatalf("compact node of uint32 key and value takes %d bytes, want 20", size)
+    }
+
+    tree := NewCompactTree[int, int]()
+    expected := make(map[int]int)
+    r := rand.New(rand.NewSource(22))
+    for i := 0; i < 50000; i++ {
+        k := r.Intn(5000)
+        if r.Intn(3) == 0 {
+            tree.Delete(k)
+            delete(expected, k)
+        } else {
+            tree.Insert(k, i)
+            expected[k] = i
+        }
+    }
+    checkCompactTree(t, tree, tree.root)
+    if tree.Count() != len(expected) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(expected))
+    }
+    for k, v := range expected {
+        if value, ok := tree.Get(k); !ok || value != v {
+            t.Fatalf("Get(%d) = %d, %v, want %d", k, value, ok, v)
+        }
+    }
+    if len(tree.nodes) > 5001 {
+        t.Fatalf("released nodes not reused, %d nodes for 5000 keys", len(tree.nodes))
+    }
+
+    previous, count := -1, 0
+    for key, value := range tree.All() {
+        if key <= previous || value != expected[key] {
+            t.Fatalf("All() yielded %d after %d", key, previous)
+        }
+        previous = key
+        count++
+    }
+    if count != len(expected) {
+        t.Fatalf("All() yielded %d items, want %d", count, len(expected))
+    }
+
+    previous = 5000
+    for key := range tree.Backward() {
+        if key >= previous {
+            t.Fatalf("Backward() yielded %d after %d", key, previous)
+        }
+        previous = key
+    }
+
+    count = 0
+    for key := range tree.Range(2000, 1000) {
+        if key < 1000 || key > 2000 {
+            t.Fatalf("Range(1000, 2000) yielded %d", key)
+        }
+        count++
+    }
+    want := 0
+    for k := range expected {
+        if k >= 1000 && k <= 2000 {
+            want++
+        }
+    }
+    if count != want {
+        t.Fatalf("Range(1000, 2000) yielded %d items, want %d", count, want)
+    }
+    for key := range tree.Until(10) {
+        if key >= 10 {
+            t.Fatalf("Until(10) yielded %d", key)
+        }
+    }
+    for key := range tree.From(4990) {
+        if key < 4990 {
+            t.Fatalf("From(4990) yielded %d", key)
+        }
+    }
+
+    tree.Delete(-1)
+    for k := range expected {
+        tree.Delete(k)
+        checkCompactTree(t, tree, tree.root)
+    }
+    if !tree.IsEmpty() || tree.Count() != 0 {
+        t.Fatalf("tree not empty after deleting all keys")
+    }
+    if _, _, ok := tree.Min(); ok {
+        t.Fatalf("Min() of an empty tree succeeded")
+    }
+}
+
+func TestCompactTreeFloorCeiling(t *testing.T) {
+    tree := NewCompactTreeWithComparator[string, int](func(a, b string) KeyComparison {
+        return compareOrdered(a, b)
+    })
+    tree.Grow(100)
+    capacity := cap(tree.nodes)
+    for i := 0; i < 100; i += 2 {
+        tree.Insert(string(rune('a' + i / 10)) + string(rune('0' + i % 10)), i)
+    }
+    if cap(tree.nodes) != capacity {
+        t.Fatalf("storage reallocated after Grow(100)")
+    }
+
+    if key, value, ok := tree.Floor("a5"); !ok || key != "a4" || value != 4 {
+        t.Fatalf("Floor(a5) = %s, %d, %v", key, value, ok)
+    }
+    if key, _, ok := tree.Ceiling("a5"); !ok || key != "a6" {
+        t.Fatalf("Ceiling(a5) = %s, %v", key, ok)
+    }
+    if _, _, ok := tree.Ceiling("z"); ok {
+        t.Fatalf("Ceiling(z) succeeded")
+    }
+    if key, _, _ := tree.Min(); key != "a0" {
+        t.Fatalf("Min() = %s, want a0", key)
+    }
+    if key, _, _ := tree.Max(); key != "j8" {
+        t.Fatalf("Max() = %s, want j8", key)
+    }
+
+    defer func() {
+        if r := recover(); r != ErrEnumeratorModified {
+            t.Fatalf("modifying while ranging recovered %v, want ErrEnumeratorModified", r)
+        }
+    }()
+    for key := range tree.All() {
+        tree.Delete(key)
+    }
+}
+
+func TestCompactTreeRankSelect(t *testing.T) {
+    tree := NewCompactTree[int, int]()
+    present := make(map[int]bool)
+    r := rand.New(rand.NewSource(7))
+    for i := 0; i < 20000; i++ {
+        k := r.Intn(2000) * 2
+        if r.Intn(3) == 0 {
+            tree.Delete(k)
+            delete(present, k)
+        } else {
+            tree.Insert(k, k)
+            present[k] = true
+        }
+    }
+    checkCompactTree(t, tree, tree.root)
+
+    var keys []int
+    for key := range tree.All() {
+        keys = append(keys, key)
+    }
+    for index, k := range keys {
+        if rank := tree.Rank(k); rank != index {
+            t.Fatalf("Rank(%d) = %d, want %d", k, rank, index)
+        }
+        if rank := tree.Rank(k + 1); rank != index + 1 {
+            t.Fatalf("Rank(%d) = %d, want %d", k + 1, rank, index + 1)
+        }
+        if key, value, ok := tree.Select(index); !ok || key != k || value != k {
+            t.Fatalf("Select(%d) = %d, %d, %v, want %d", index, key, value, ok, k)
+        }
+    }
+    if _, _, ok := tree.Select(len(keys)); ok {
+        t.Fatalf("Select(%d) past the end succeeded", len(keys))
+    }
+    if _, _, ok := tree.Select(-1); ok {
+        t.Fatalf("Select(-1) succeeded")
+    }
+
+    want := 0
+    for k := range present {
+        if k >= 1000 && k <= 3000 {
+            want++
+        }
+    }
+    if count := tree.CountBetween(3000, 1000); count != want {
+        t.Fatalf("CountBetween(1000, 3000) = %d, want %d", count, want)
+    }
+
+    for index := 1; index < len(keys) - 1; index++ {
+        if key, _, ok := tree.Successor(keys[index]); !ok || key != keys[index + 1] {
+            t.Fatalf("Successor(%d) = %d, %v, want %d", keys[index], key, ok, keys[index + 1])
+        }
+        if key, _, ok := tree.Predecessor(keys[index] + 1); !ok || key != keys[index] {
+            t.Fatalf("Predecessor(%d) = %d, %v, want %d", keys[index] + 1, key, ok, keys[index])
+        }
+    }
+    if _, _, ok := tree.Successor(keys[len(keys) - 1]); ok {
+        t.Fatalf("Successor of the maximum succeeded")
+    }
+    if _, _, ok := tree.Predecessor(keys[0]); ok {
+        t.Fatalf("Predecessor of the minimum succeeded")
+    }
+}
+
+func TestCompactIterator(t *testing.T) {
+    tree := NewCompactTree[int, int]()
+    for i := 0; i < 100; i++ {
+        tree.Insert(i, i * 10)
+    }
+
+    var keys []int
+    closeAt := -1
+    iterator, err := tree.NewIterator(func(iterator *CompactIterator[int, int], key int, value int) {
+        if value != key * 10 {
+            t.Errorf("callback got %d for key %d", value, key)
+        }
+        keys = append(keys, key)
+        if key == closeAt {
+            iterator.Close()
+        }
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if iterator.Tree() != tree {
+        t.Fatalf("Tree() returned another tree")
+    }
+
+    tests := []struct {
+        name string
+        run func() (int, error)
+        first, last, count int
+    }{
+        {"All", iterator.All, 0, 99, 100},
+        {"AllDesc", iterator.AllDesc, 99, 0, 100},
+        {"Between", func() (int, error) { return iterator.Between(60, 20) }, 20, 60, 41},
+        {"BetweenDesc", func() (int, error) { return iterator.BetweenDesc(20, 60) }, 60, 20, 41},
+        {"LessOrEqual", func() (int, error) { return iterator.LessOrEqual(10) }, 0, 10, 11},
+        {"LessThanDesc", func() (int, error) { return iterator.LessThanDesc(10) }, 9, 0, 10},
+        {"GreaterOrEqualDesc", func() (int, error) { return iterator.GreaterOrEqualDesc(90) }, 99, 90, 10},
+        {"GreaterThan", func() (int, error) { return iterator.GreaterThan(90) }, 91, 99, 9},
+    }
+    for _, test := range tests {
+        keys = nil
+        count, err := test.run()
+        if err != nil || count != test.count || len(keys) != test.count ||
+            keys[0] != test.first || keys[len(keys) - 1] != test.last {
+            t.Fatalf("%s() = %d, %v, yielded %v", test.name, count, err, keys)
+        }
+        for i := 1; i < len(keys); i++ {
+            if (keys[i] > keys[i - 1]) != (test.first < test.last) {
+                t.Fatalf("%s() yielded %d after %d", test.name, keys[i], keys[i - 1])
+            }
+        }
+    }
+
+    keys, closeAt = nil, 80
+    if count, err := iterator.GreaterOrEqual(75); err != nil || count != 6 || !iterator.Closed() {
+        t.Fatalf("GreaterOrEqual(75) closed at 80 = %d, %v, closed %v", count, err, iterator.Closed())
+    }
+    if _, err := iterator.All(); err != ErrIteratorClosed {
+        t.Fatalf("All() on a closed iterator = %v, want ErrIteratorClosed", err)
+    }
+
+    iterator, _ = tree.NewIterator(func(iterator *CompactIterator[int, int], key int, value int) {
+        tree.Delete(key)
+    })
+    if count, err := iterator.All(); err != ErrEnumeratorModified || count != 1 {
+        t.Fatalf("All() deleting in the callback = %d, %v, want 1, ErrEnumeratorModified", count, err)
+    }
+    if _, err := tree.NewIterator(nil); err == nil {
+        t.Fatalf("NewIterator(nil) succeeded")
+    }
+}
+
+func TestCompactCursor(t *testing.T) {
+    tree := NewCompactTree[int, int]()
+    for i := 0; i < 10; i++ {
+        tree.Insert(i * 2, i)
+    }
+
+    cursor := tree.NewCursor()
+    if cursor.Valid() || cursor.Next() || cursor.Prev() {
+        t.Fatalf("unpositioned cursor moved")
+    }
+
+    var keys []int
+    for ok := cursor.SeekFirst(); ok; ok = cursor.Next() {
+        if cursor.Value() != cursor.Key() / 2 {
+            t.Fatalf("Value() = %d at key %d", cursor.Value(), cursor.Key())
+        }
+        keys = append(keys, cursor.Key())
+    }
+    if len(keys) != 10 || keys[0] != 0 || keys[9] != 18 || cursor.Valid() {
+        t.Fatalf("ascending cursor yielded %v", keys)
+    }
+
+    keys = nil
+    for ok := cursor.SeekLast(); ok; ok = cursor.Prev() {
+        keys = append(keys, cursor.Key())
+    }
+    if len(keys) != 10 || keys[0] != 18 || keys[9] != 0 {
+        t.Fatalf("descending cursor yielded %v", keys)
+    }
+
+    if !cursor.Seek(7) || cursor.Key() != 8 {
+        t.Fatalf("Seek(7) positioned at %d, want 8", cursor.Key())
+    }
+    tree.Delete(8)
+    if cursor.Valid() || cursor.Key() != 0 {
+        t.Fatalf("cursor valid at the deleted key")
+    }
+    if !cursor.Next() || cursor.Key() != 10 {
+        t.Fatalf("Next() after deleting the position key moved to %d, want 10", cursor.Key())
+    }
+    tree.Insert(9, 100)
+    if !cursor.Prev() || cursor.Key() != 9 || cursor.Value() != 100 {
+        t.Fatalf("Prev() after inserting 9 moved to %d, want 9", cursor.Key())
+    }
+    if cursor.Seek(19) {
+        t.Fatalf("Seek(19) past the maximum succeeded")
+    }
+}
+
+func TestCompactRbTree(t *testing.T) {
+    tree := NewCompactRbTree()
+    for i := 9; i >= 0; i-- {
+        key := IntKey(i)
+        tree.Insert(&key, i)
+    }
+    checkCompactTree(t, tree, tree.root)
+
+    key := IntKey(4)
+    if value, ok := tree.Get(&key); !ok || value != 4 {
+        t.Fatalf("Get(4) = %v, %v", value, ok)
+    }
+    if rank := tree.Rank(&key); rank != 4 {
+        t.Fatalf("Rank(4) = %d, want 4", rank)
+    }
+    previous := -1
+    for key, value := range tree.All() {
+        if int(*key.(*IntKey)) != previous + 1 || value != previous + 1 {
+            t.Fatalf("All() yielded %v after %d", key, previous)
+        }
+        previous++
+    }
+}
+
+func TestCompactTreeCapacity(t *testing.T) {
+    defer func(maxIndex uint32) {
+        compactMaxIndex = maxIndex
+    }(compactMaxIndex)
+    compactMaxIndex = 4
+
+    tree := NewCompactTree[int, int]()
+    for i := 0; i < 4; i++ {
+        if err := tree.TryInsert(i, i); err != nil {
+            t.Fatalf("TryInsert(%d) = %v", i, err)
+        }
+    }
+    if err := tree.TryInsert(4, 4); err != ErrCapacityExceeded {
+        t.Fatalf("TryInsert on a full tree = %v, want ErrCapacityExceeded", err)
+    }
+    if err := tree.TryInsert(2, 20); err != nil {
+        t.Fatalf("TryInsert of an existing key on a full tree = %v", err)
+    }
+    if value, _ := tree.Get(2); value != 20 || tree.Count() != 4 {
+        t.Fatalf("Get(2) = %d, Count() = %d, want 20, 4", value, tree.Count())
+    }
+
+    tree.Delete(0)
+    if err := tree.TryInsert(4, 4); err != nil {
+        t.Fatalf("TryInsert after a delete = %v", err)
+    }
+    checkCompactTree(t, tree, tree.root)
+
+    defer func() {
+        if r := recover(); r != ErrCapacityExceeded {
+            t.Fatalf("Insert on a full tree recovered %v, want ErrCapacityExceeded", r)
+        }
+    }()
+    tree.Insert(5, 5)
+}
+
+func BenchmarkCompactTreeChurn(b *testing.B) {
+    r := rand.New(rand.NewSource(22))
+    keys := make([]uint64, 1 << 16)
+    for i := range keys {
+        keys[i] = r.Uint64()
+    }
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        tree := NewCompactTree[uint64, uint64]()
+        for _, key := range keys {
+            tree.Insert(key, key)
+        }
+        for _, key := range keys {
+            tree.Delete(key)
+        }
+    }
+}
+
+func BenchmarkTreeChurn(b *testing.B) {
+    r := rand.New(rand.NewSource(22))
+    keys := make([]uint64, 1 << 16)
+    for i := range keys {
+        keys[i] = r.Uint64()
+    }
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        tree := NewTree[uint64, uint64]()
+        for _, key := range keys {
+            tree.Insert(key, key)
+        }
+        for _, key := range keys {
+            tree.Delete(key)
+        }
+    }
+}
@@ -0,0 +1,107 @@
+package rbt
+
+// CompactCursor structure used for pull-style iteration on a CompactTree.
+// A cursor keeps the key of its position, so modifying the tree
+// while the cursor is open does not invalidate the cursor, stepping
+// continues from the position key even if it has been deleted.
+// The nodes have no parent links, so each step searches from the root in O(log n).
+type CompactCursor[K any, V any] struct {
+    tree *CompactTree[K, V]
+    key K
+    node uint32
+    positioned bool
+    version uint32
+}
+
+// NewCursor creates a new unpositioned cursor for the given CompactTree
+func (tree *CompactTree[K, V]) NewCursor() *CompactCursor[K, V] {
+    return &CompactCursor[K, V]{
+        tree: tree,
+    }
+}
+
+// Tree returns the CompactTree that the cursor is moving on
+func (cursor *CompactCursor[K, V]) Tree() *CompactTree[K, V] {
+    return cursor.tree
+}
+
+// moveTo positions the cursor at the given node, clears the position if the node is 0
+func (cursor *CompactCursor[K, V]) moveTo(i uint32) bool {
+    cursor.node = i
+    cursor.version = cursor.tree.version
+    cursor.positioned = i != 0
+    if i == 0 {
+        var zero K
+        cursor.key = zero
+        return false
+    }
+    cursor.key = cursor.tree.nodes[i].key
+    return true
+}
+
+// current returns the node at the cursor position refreshing it
+// if the tree has been modified since the cursor has moved
+func (cursor *CompactCursor[K, V]) current() uint32 {
+    if cursor.positioned && cursor.version != cursor.tree.version {
+        cursor.node = cursor.tree.find(cursor.key)
+        cursor.version = cursor.tree.version
+    }
+    return cursor.node
+}
+
+// Seek moves the cursor to the smallest key greater or equal to the given key,
+// returns 'false' if there is no such key
+func (cursor *CompactCursor[K, V]) Seek(key K) bool {
+    return cursor.moveTo(cursor.tree.ceiling(key))
+}
+
+// SeekFirst moves the cursor to the smallest key in the tree,
+// returns 'false' if the tree is empty
+func (cursor *CompactCursor[K, V]) SeekFirst() bool {
+    return cursor.moveTo(cursor.tree.min(cursor.tree.root))
+}
+
+// SeekLast moves the cursor to the largest key in the tree,
+// returns 'false' if the tree is empty
+func (cursor *CompactCursor[K, V]) SeekLast() bool {
+    return cursor.moveTo(cursor.tree.max(cursor.tree.root))
+}
+
+// Next moves the cursor to the next key in ascending order,
+// returns 'false' if the cursor is not positioned or there is no next key
+func (cursor *CompactCursor[K, V]) Next() bool {
+    if !cursor.positioned {
+        return false
+    }
+    return cursor.moveTo(cursor.tree.higher(cursor.key))
+}
+
+// Prev moves the cursor to the previous key in ascending order,
+// returns 'false' if the cursor is not positioned or there is no previous key
+func (cursor *CompactCursor[K, V]) Prev() bool {
+    if !cursor.positioned {
+        return false
+    }
+    return cursor.moveTo(cursor.tree.lower(cursor.key))
+}
+
+// Valid returns 'true' if the cursor is positioned on a key existing in the tree
+func (cursor *CompactCursor[K, V]) Valid() bool {
+    return cursor.current() != 0
+}
+
+// Key returns the key at the cursor position, the zero key if the cursor is not valid
+func (cursor *CompactCursor[K, V]) Key() (key K) {
+    if cursor.current() != 0 {
+        key = cursor.key
+    }
+    return
+}
+
+// Value returns the value at the cursor position, the zero value if the cursor is not valid
+func (cursor *CompactCursor[K, V]) Value() (value V) {
+    if i := cursor.current(); i != 0 {
+        value = cursor.tree.nodes[i].value
+    }
+    return
+}
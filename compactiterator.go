@@ -0,0 +1,226 @@
+package rbt
+
+// CompactIterationCallback is the function used to by the CompactIterator
+// with will be called on iteration match
+type CompactIterationCallback[K any, V any] func(iterator *CompactIterator[K, V], key K, value V)
+
+// CompactIterator structure used for iterating on a CompactTree
+type CompactIterator[K any, V any] struct {
+    tree *CompactTree[K, V]
+    count int
+    state int32
+    version uint32
+    callback CompactIterationCallback[K, V]
+    data map[string]interface{}
+}
+
+// NewIterator creates a new iterator for the given CompactTree
+func (tree *CompactTree[K, V]) NewIterator(callback CompactIterationCallback[K, V]) (*CompactIterator[K, V], error) {
+    if tree == nil {
+        return nil, ArgumentNilError("tree")
+    }
+    if callback == nil {
+        return nil, ArgumentNilError("callback")
+    }
+
+    return &CompactIterator[K, V]{
+        tree: tree,
+        version: tree.version,
+        callback: callback,
+        state: iteratorReady,
+    }, nil
+}
+
+// Tree returns the CompactTree that the iterator is iterating on
+func (iterator *CompactIterator[K, V]) Tree() *CompactTree[K, V] {
+    return iterator.tree
+}
+
+// CurrentCount gives the count of the items that match the iteration case
+func (iterator *CompactIterator[K, V]) CurrentCount() int {
+    return iterator.count
+}
+
+// Close closes the current iteration, so the iteration stops iterating
+func (iterator *CompactIterator[K, V]) Close() {
+    iterator.state = iteratorClosed
+    iterator.tree = nil
+}
+
+// Closed gives the state of the iterator, 'true' if closed
+func (iterator *CompactIterator[K, V]) Closed() bool {
+    return iterator.state == iteratorClosed
+}
+
+// GetData returns the data stored on the iterator with the dataKey
+func (iterator *CompactIterator[K, V]) GetData(dataKey string) (interface{}, bool) {
+    result, ok := iterator.data[dataKey]
+    return result, ok
+}
+
+// SetData stores the data with the dataKey on the iterator
+func (iterator *CompactIterator[K, V]) SetData(dataKey string, value interface{}) {
+    if iterator.data == nil {
+        iterator.data = make(map[string]interface{})
+    }
+    iterator.data[dataKey] = value
+}
+
+// RemoveData deletes the data stored on the iterator with the dataKey
+func (iterator *CompactIterator[K, V]) RemoveData(dataKey string) {
+    delete(iterator.data, dataKey)
+}
+
+// ClearData clears all the data stored on the iterator
+func (iterator *CompactIterator[K, V]) ClearData() {
+    iterator.data = nil
+}
+
+// All iterates on all items of the CompactTree
+func (iterator *CompactIterator[K, V]) All() (int, error) {
+    return iterator.iterate(nil, nil, false)
+}
+
+// AllDesc iterates on all items of the CompactTree in descending order
+func (iterator *CompactIterator[K, V]) AllDesc() (int, error) {
+    return iterator.iterate(nil, nil, true)
+}
+
+// Between iterates on the items of the CompactTree that the key of the item
+// is greater or equal to loKey and less or equal to hiKey
+func (iterator *CompactIterator[K, V]) Between(loKey K, hiKey K) (int, error) {
+    lo, hi := iterator.between(loKey, hiKey)
+    return iterator.iterate(lo, hi, false)
+}
+
+// BetweenDesc iterates on the items of the CompactTree that the key of the item
+// is greater or equal to loKey and less or equal to hiKey in descending order
+func (iterator *CompactIterator[K, V]) BetweenDesc(loKey K, hiKey K) (int, error) {
+    lo, hi := iterator.between(loKey, hiKey)
+    return iterator.iterate(lo, hi, true)
+}
+
+// between returns the inclusive bounds of the keys in ascending order
+func (iterator *CompactIterator[K, V]) between(loKey K, hiKey K) (lo, hi *treeBound[K]) {
+    if iterator.tree != nil && iterator.tree.compare(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return &treeBound[K]{key: loKey, inclusive: true}, &treeBound[K]{key: hiKey, inclusive: true}
+}
+
+// LessOrEqual iterates on the items of the CompactTree that the key of the item
+// is less or equal to the given key
+func (iterator *CompactIterator[K, V]) LessOrEqual(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key, inclusive: true}, false)
+}
+
+// LessOrEqualDesc iterates on the items of the CompactTree that the key of the item
+// is less or equal to the given key in descending order
+func (iterator *CompactIterator[K, V]) LessOrEqualDesc(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key, inclusive: true}, true)
+}
+
+// LessThan iterates on the items of the CompactTree that the key of the item
+// is less than the given key
+func (iterator *CompactIterator[K, V]) LessThan(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key}, false)
+}
+
+// LessThanDesc iterates on the items of the CompactTree that the key of the item
+// is less than the given key in descending order
+func (iterator *CompactIterator[K, V]) LessThanDesc(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key}, true)
+}
+
+// GreaterOrEqual iterates on the items of the CompactTree that the key of the item
+// is greater or equal to the given key
+func (iterator *CompactIterator[K, V]) GreaterOrEqual(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key, inclusive: true}, nil, false)
+}
+
+// GreaterOrEqualDesc iterates on the items of the CompactTree that the key of the item
+// is greater or equal to the given key in descending order
+func (iterator *CompactIterator[K, V]) GreaterOrEqualDesc(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key, inclusive: true}, nil, true)
+}
+
+// GreaterThan iterates on the items of the CompactTree that the key of the item
+// is greater than the given key
+func (iterator *CompactIterator[K, V]) GreaterThan(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key}, nil, false)
+}
+
+// GreaterThanDesc iterates on the items of the CompactTree that the key of the item
+// is greater than the given key in descending order
+func (iterator *CompactIterator[K, V]) GreaterThanDesc(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key}, nil, true)
+}
+
+// iterate walks on the items of the CompactTree between the given bounds,
+// a nil bound leaves that side of the range open
+func (iterator *CompactIterator[K, V]) iterate(lo, hi *treeBound[K], descending bool) (int, error) {
+    switch iterator.state {
+    case iterWalking:
+        return 0, ErrIteratorAlreadyRunning
+    case iteratorClosed:
+        return 0, ErrIteratorClosed
+    case iteratorUninitialized:
+        return 0, ErrIteratorUninitialized
+    }
+    if iterator.tree == nil {
+        return 0, ErrIteratorClosed
+    }
+
+    iterator.count = 0
+    iterator.state = iterWalking
+    iterator.version = iterator.tree.version
+
+    var err error
+    if iterator.tree.root != 0 {
+        _, err = iterator.walk(iterator.tree.root, lo, hi, descending)
+    }
+    if iterator.state == iterWalking {
+        iterator.state = iteratorReady
+    }
+    return iterator.count, err
+}
+
+// walk iterates in order on the subtree rooted at the node, returns 'false' if the walk ended
+func (iterator *CompactIterator[K, V]) walk(i uint32, lo, hi *treeBound[K], descending bool) (bool, error) {
+    for i != 0 {
+        tree := iterator.tree
+        if tree == nil || iterator.version != tree.version {
+            return false, ErrEnumeratorModified
+        }
+
+        key := tree.nodes[i].key
+        inLo := lo == nil || tree.aboveLo(key, lo)
+        inHi := hi == nil || tree.belowHi(key, hi)
+        first, last, inFirst, inLast := tree.left(i), tree.right(i), inLo, inHi
+        if descending {
+            first, last, inFirst, inLast = last, first, inHi, inLo
+        }
+
+        if inFirst {
+            if first != 0 {
+                if ok, err := iterator.walk(first, lo, hi, descending); !ok {
+                    return false, err
+                }
+            }
+            if !inLast {
+                return false, nil
+            }
+            if iterator.version != tree.version {
+                return false, ErrEnumeratorModified
+            }
+
+            iterator.count++
+            iterator.callback(iterator, key, tree.nodes[i].value)
+            if iterator.state != iterWalking {
+                return false, nil
+            }
+        }
+        i = last
+    }
+    return true, nil
+}
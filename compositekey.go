@@ -0,0 +1,85 @@
+package rbt
+
+// CompositeKey is the tuple key for RbKey made of RbKey components compared in order.
+// The components at the same position of the keys in a tree should be of the same key type,
+// a key being a prefix of another key is less than the other key.
+type CompositeKey []RbKey
+
+// DescendingKey is the key for RbKey reversing the order of the wrapped key,
+// used for the descending components of a CompositeKey
+type DescendingKey struct {
+    Key RbKey
+}
+
+// boundKey is the key for RbKey less or greater than all other keys
+type boundKey struct {
+    cmp KeyComparison
+}
+
+var (
+    // MinKey is less than all other keys as a component of a CompositeKey
+    MinKey RbKey = &boundKey{cmp: KeyIsLess}
+    // MaxKey is greater than all other keys as a component of a CompositeKey
+    MaxKey RbKey = &boundKey{cmp: KeyIsGreater}
+)
+
+// NewCompositeKey creates a new CompositeKey from the components and returns its address
+func NewCompositeKey(components ...RbKey) *CompositeKey {
+    ckey := CompositeKey(components)
+    return &ckey
+}
+
+// PrefixRange returns the lowest and highest keys for the keys starting with the given components,
+// to be used as the loKey and hiKey of Between or Range.
+func PrefixRange(prefix ...RbKey) (loKey *CompositeKey, hiKey *CompositeKey) {
+    hi := make(CompositeKey, len(prefix), len(prefix) + 1)
+    copy(hi, prefix)
+    hi = append(hi, MaxKey)
+    return NewCompositeKey(prefix...), &hi
+}
+
+// Desc creates a new DescendingKey wrapping the key and returns its address
+func Desc(key RbKey) *DescendingKey {
+    return &DescendingKey{Key: key}
+}
+
+// ComparedTo compares the given RbKey with its self
+func (ckey *CompositeKey) ComparedTo(key RbKey) KeyComparison {
+    other := *key.(*CompositeKey)
+    for i, component := range *ckey {
+        if i >= len(other) {
+            return KeyIsGreater
+        }
+        if cmp := compareComponents(component, other[i]); cmp != KeysAreEqual {
+            return cmp
+        }
+    }
+    if len(*ckey) < len(other) {
+        return KeyIsLess
+    }
+    return KeysAreEqual
+}
+
+// compareComponents compares the components of the composite keys handling MinKey and MaxKey
+func compareComponents(a, b RbKey) KeyComparison {
+    if _, ok := a.(*boundKey); ok {
+        return a.ComparedTo(b)
+    }
+    if _, ok := b.(*boundKey); ok {
+        return -b.ComparedTo(a)
+    }
+    return a.ComparedTo(b)
+}
+
+// ComparedTo compares the given RbKey with its self
+func (dkey *DescendingKey) ComparedTo(key RbKey) KeyComparison {
+    return -compareComponents(dkey.Key, key.(*DescendingKey).Key)
+}
+
+// ComparedTo compares the given RbKey with its self
+func (bkey *boundKey) ComparedTo(key RbKey) KeyComparison {
+    if other, ok := key.(*boundKey); ok && other.cmp == bkey.cmp {
+        return KeysAreEqual
+    }
+    return bkey.cmp
+}
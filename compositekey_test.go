@@ -0,0 +1,84 @@
+package rbt
+
+import (
+    "bytes"
+    "testing"
+)
+
+func TestCompositeKey(t *testing.T) {
+    tree := NewRbTree()
+    tenants := []string{"b", "a", "c"}
+    for _, tenant := range tenants {
+        for ts := 0; ts < 5; ts++ {
+            for id := 0; id < 3; id++ {
+                key := NewCompositeKey(newKey(StringKey(tenant)), Desc(newKey(Int64Key(ts))), newKey(IntKey(id)))
+                tree.Insert(key, tenant)
+            }
+        }
+    }
+    if tree.Count() != 45 {
+        t.Fatalf("Count() = %d, want 45", tree.Count())
+    }
+    checkRbTree(t, tree.root)
+
+    var previous *CompositeKey
+    for key := range tree.All() {
+        current := key.(*CompositeKey)
+        if previous != nil {
+            tenant, lastTenant := *(*current)[0].(*StringKey), *(*previous)[0].(*StringKey)
+            ts, lastTs := *(*current)[1].(*DescendingKey).Key.(*Int64Key), *(*previous)[1].(*DescendingKey).Key.(*Int64Key)
+            if tenant < lastTenant || (tenant == lastTenant && ts > lastTs) {
+                t.Fatalf("keys out of order: %v after %v", *current, *previous)
+            }
+        }
+        previous = current
+    }
+
+    lo, hi := PrefixRange(newKey(StringKey("b")))
+    count := 0
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        if value != "b" {
+            t.Fatalf("Between of prefix b yielded tenant %v", value)
+        }
+        count++
+    })
+    if n, err := iterator.Between(lo, hi); err != nil || n != 15 || count != 15 {
+        t.Fatalf("Between of prefix b = %d, %v, want 15", n, err)
+    }
+
+    lo, hi = PrefixRange(newKey(StringKey("c")), Desc(newKey(Int64Key(4))))
+    count = 0
+    for key := range tree.Range(lo, hi) {
+        if *(*key.(*CompositeKey))[1].(*DescendingKey).Key.(*Int64Key) != 4 {
+            t.Fatalf("Range of prefix c, 4 yielded %v", key)
+        }
+        count++
+    }
+    if count != 3 {
+        t.Fatalf("Range of prefix c, 4 yielded %d keys, want 3", count)
+    }
+
+    first, _ := tree.Min()
+    if *(*first.(*CompositeKey))[1].(*DescendingKey).Key.(*Int64Key) != 4 {
+        t.Fatalf("Min() = %v, want the latest timestamp first", first)
+    }
+
+    short := NewCompositeKey(newKey(StringKey("a")))
+    long := NewCompositeKey(newKey(StringKey("a")), newKey(IntKey(0)))
+    if short.ComparedTo(long) != KeyIsLess || long.ComparedTo(short) != KeyIsGreater || short.ComparedTo(short) != KeysAreEqual {
+        t.Fatalf("prefix key not ordered before the longer key")
+    }
+    if MinKey.ComparedTo(MaxKey) != KeyIsLess || MaxKey.ComparedTo(MaxKey) != KeysAreEqual {
+        t.Fatalf("MinKey and MaxKey misordered")
+    }
+
+    var buf bytes.Buffer
+    if _, err := tree.WriteTo(&buf); err != nil {
+        t.Fatal(err)
+    }
+    result := NewRbTree()
+    if _, err := result.ReadFrom(&buf); err != nil {
+        t.Fatal(err)
+    }
+    checkSameItems(t, result, tree)
+}
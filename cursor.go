@@ -0,0 +1,119 @@
+package rbt
+
+// Cursor structure used for pull-style iteration on a RbTree.
+// A cursor keeps the key of its position, so modifying the tree
+// while the cursor is open does not invalidate the cursor, stepping
+// continues from the position key even if it has been deleted.
+type Cursor struct {
+    tree *RbTree
+    key RbKey
+    node *rbNode
+    version uint32
+}
+
+// NewCursor creates a new unpositioned cursor for the given RbTree
+func (tree *RbTree) NewCursor() *Cursor {
+    return &Cursor{
+        tree: tree,
+    }
+}
+
+// Tree returns the RbTree that the cursor is moving on
+func (cursor *Cursor) Tree() *RbTree {
+    return cursor.tree
+}
+
+// moveTo positions the cursor at the given node, clears the position if node is nil
+func (cursor *Cursor) moveTo(node *rbNode) bool {
+    cursor.node = node
+    cursor.version = cursor.tree.version
+    if node == nil {
+        cursor.key = nil
+        return false
+    }
+    cursor.key = node.key
+    return true
+}
+
+// current returns the node at the cursor position refreshing it
+// if the tree has been modified since the cursor has moved
+func (cursor *Cursor) current() *rbNode {
+    if cursor.key != nil && cursor.version != cursor.tree.version {
+        cursor.node = cursor.tree.find(cursor.key)
+        cursor.version = cursor.tree.version
+    }
+    return cursor.node
+}
+
+// Seek moves the cursor to the smallest key greater or equal to the given key,
+// returns 'false' if there is no such key
+func (cursor *Cursor) Seek(key RbKey) bool {
+    if key == nil {
+        return cursor.moveTo(nil)
+    }
+    return cursor.moveTo(ceiling(cursor.tree.root, key, cursor.tree.compare))
+}
+
+// SeekFirst moves the cursor to the smallest key in the tree,
+// returns 'false' if the tree is empty
+func (cursor *Cursor) SeekFirst() bool {
+    return cursor.moveTo(min(cursor.tree.root))
+}
+
+// SeekLast moves the cursor to the largest key in the tree,
+// returns 'false' if the tree is empty
+func (cursor *Cursor) SeekLast() bool {
+    return cursor.moveTo(max(cursor.tree.root))
+}
+
+// Next moves the cursor to the next key in ascending order,
+// returns 'false' if the cursor is not positioned or there is no next key.
+// Stepping follows the parent links in O(1) amortized while the tree is not modified.
+func (cursor *Cursor) Next() bool {
+    if cursor.key == nil {
+        return false
+    }
+    if cursor.linked() {
+        return cursor.moveTo(successor(cursor.node))
+    }
+    return cursor.moveTo(higher(cursor.tree.root, cursor.key, cursor.tree.compare))
+}
+
+// Prev moves the cursor to the previous key in ascending order,
+// returns 'false' if the cursor is not positioned or there is no previous key.
+// Stepping follows the parent links in O(1) amortized while the tree is not modified.
+func (cursor *Cursor) Prev() bool {
+    if cursor.key == nil {
+        return false
+    }
+    if cursor.linked() {
+        return cursor.moveTo(predecessor(cursor.node))
+    }
+    return cursor.moveTo(lower(cursor.tree.root, cursor.key, cursor.tree.compare))
+}
+
+// linked returns 'true' if the cursor can step from its node following the parent links
+func (cursor *Cursor) linked() bool {
+    return cursor.node != nil && cursor.version == cursor.tree.version && cursor.tree.linked()
+}
+
+// Valid returns 'true' if the cursor is positioned on a key existing in the tree
+func (cursor *Cursor) Valid() bool {
+    return cursor.current() != nil
+}
+
+// Key returns the key at the cursor position, nil if the cursor is not valid
+func (cursor *Cursor) Key() RbKey {
+    if node := cursor.current(); node != nil {
+        return cursor.key
+    }
+    return nil
+}
+
+// Value returns the value at the cursor position, nil if the cursor is not valid
+func (cursor *Cursor) Value() interface{} {
+    if node := cursor.current(); node != nil {
+        return node.value
+    }
+    return nil
+}
@@ -0,0 +1,544 @@
+package rbt
+
+import (
+    "bufio"
+    "encoding/binary"
+    "hash/crc32"
+    "io"
+    "iter"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// SyncPolicy is used for deciding when the write-ahead log of a DurableRbTree gets fsynced
+type SyncPolicy int
+
+const (
+    // SyncAlways fsyncs the log after every Insert and Delete
+    SyncAlways SyncPolicy = iota
+    // SyncInterval fsyncs the log on the first Insert or Delete after DurableOptions.SyncInterval has elapsed
+    // since the last fsync, the records written after the last fsync may be lost on a crash
+    SyncInterval
+    // SyncNever leaves flushing the log to the operating system until Sync, Checkpoint or Close is called
+    SyncNever
+)
+
+const (
+    // durableSnapshotFile is the name of the checkpoint file in the directory of a DurableRbTree
+    durableSnapshotFile = "snapshot.rbt"
+    // durableLogFile is the name of the write-ahead log file in the directory of a DurableRbTree
+    durableLogFile = "wal.log"
+    // durableRecordHeaderSize is the size of the length and the checksum preceding each log record
+    durableRecordHeaderSize = 8
+    // durableMaxRecordSize is the largest valid log record payload
+    durableMaxRecordSize = 1 << 30
+)
+
+// Operations of the write-ahead log records
+const (
+    durableInsertOp byte = iota + 1
+    durableDeleteOp
+)
+
+// DurableOptions structure holds the options of a DurableRbTree
+type DurableOptions struct {
+    // SyncPolicy decides when the log gets fsynced
+    SyncPolicy SyncPolicy
+    // SyncInterval is the least time between two fsyncs with the SyncInterval policy
+    SyncInterval time.Duration
+    // CheckpointRecords is the count of the log records triggering a checkpoint, zero disables
+    CheckpointRecords int
+    // CheckpointInterval is the least time between two checkpoints triggered by the modifications, zero disables
+    CheckpointInterval time.Duration
+    // ValueCodec is used for encoding the values, DefaultValueCodec is used if nil
+    ValueCodec ValueCodec
+}
+
+// DurableRbTree structure is the RbTree persisting its items in a directory.
+// Every Insert and Delete is appended to a write-ahead log before being applied,
+// the checkpoints write a full snapshot of the tree and empty the log.
+// Opening the tree loads the last snapshot and replays the log, the torn records
+// at the end of the log left by a crash are detected by their checksums and truncated.
+// A failed checkpoint triggered by a modification does not fail the modification,
+// as its record is already in the log, the failure is reported by CheckpointErr.
+//
+// All the read operations and iterations hold a read lock on the tree,
+// while the modifications and the checkpoints hold a write lock.
+type DurableRbTree struct {
+    lock sync.RWMutex
+    tree *RbTree
+    dir string
+    options DurableOptions
+    log *os.File
+    offset int64
+    records int
+    lastSync time.Time
+    lastCheckpoint time.Time
+    checkpointErr error
+    buf []byte
+}
+
+// OpenDurableRbTree opens the DurableRbTree persisted in the directory creating the directory if not exists,
+// the default options are used if options is nil
+func OpenDurableRbTree(dir string, options *DurableOptions) (*DurableRbTree, error) {
+    if len(dir) == 0 {
+        return nil, ArgumentNilError("dir")
+    }
+    if err := os.MkdirAll(dir, 0755); err != nil {
+        return nil, err
+    }
+
+    tree := &DurableRbTree{
+        tree: NewRbTree(),
+        dir: dir,
+        lastSync: time.Now(),
+        lastCheckpoint: time.Now(),
+    }
+    if options != nil {
+        tree.options = *options
+    }
+    tree.tree.SetValueCodec(tree.options.ValueCodec)
+
+    if err := tree.loadSnapshot(); err != nil {
+        return nil, err
+    }
+
+    path := filepath.Join(dir, durableLogFile)
+    _, err := os.Stat(path)
+    created := os.IsNotExist(err)
+    log, err := os.OpenFile(path, os.O_RDWR | os.O_CREATE, 0644)
+    if err != nil {
+        return nil, err
+    }
+    if created {
+        // persist the directory entry of the new log, otherwise a crash may lose the whole log
+        if err = syncDir(dir); err != nil {
+            log.Close()
+            return nil, err
+        }
+    }
+    if err = tree.replay(log); err != nil {
+        log.Close()
+        return nil, err
+    }
+    tree.log = log
+    return tree, nil
+}
+
+// loadSnapshot loads the items of the last checkpoint if exists
+func (tree *DurableRbTree) loadSnapshot() error {
+    os.Remove(filepath.Join(tree.dir, durableSnapshotFile + ".tmp"))
+
+    file, err := os.Open(filepath.Join(tree.dir, durableSnapshotFile))
+    if os.IsNotExist(err) {
+        return nil
+    }
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+
+    _, err = tree.tree.ReadFrom(bufio.NewReader(file))
+    return err
+}
+
+// replay applies the records of the log to the tree, truncates the log
+// at the first torn record and moves to the end of the log
+func (tree *DurableRbTree) replay(log *os.File) error {
+    reader := bufio.NewReader(log)
+    header := make([]byte, durableRecordHeaderSize)
+
+    for {
+        if _, err := io.ReadFull(reader, header); err != nil {
+            if err != io.EOF && err != io.ErrUnexpectedEOF {
+                return err
+            }
+            break
+        }
+
+        length := binary.LittleEndian.Uint32(header)
+        if length == 0 || length > durableMaxRecordSize {
+            break
+        }
+        payload := make([]byte, length)
+        if _, err := io.ReadFull(reader, payload); err != nil {
+            if err != io.EOF && err != io.ErrUnexpectedEOF {
+                return err
+            }
+            break
+        }
+        if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:]) {
+            break
+        }
+
+        if err := tree.apply(payload); err != nil {
+            return err
+        }
+        tree.offset += int64(durableRecordHeaderSize + len(payload))
+        tree.records++
+    }
+
+    if err := log.Truncate(tree.offset); err != nil {
+        return err
+    }
+    _, err := log.Seek(tree.offset, io.SeekStart)
+    return err
+}
+
+// apply decodes the log record and applies it to the tree
+func (tree *DurableRbTree) apply(payload []byte) error {
+    op, data := payload[0], payload[1:]
+    if op != durableInsertOp && op != durableDeleteOp {
+        return ErrInvalidFormat
+    }
+
+    key, data, err := readTaggedKey(data)
+    if err != nil {
+        return err
+    }
+    if err = tree.tree.checkKeyType(key); err != nil {
+        return err
+    }
+
+    if op == durableDeleteOp {
+        if len(data) != 0 {
+            return ErrInvalidFormat
+        }
+        tree.tree.Delete(key)
+        return nil
+    }
+
+    valueData, data, err := splitRecordBytes(data)
+    if err != nil || len(data) != 0 {
+        return ErrInvalidFormat
+    }
+    value, err := tree.tree.getValueCodec().DecodeValue(valueData)
+    if err != nil {
+        return err
+    }
+    tree.tree.Insert(key, value)
+    return nil
+}
+
+// splitRecordBytes splits the uvarint length prefixed bytes from the start of the data
+func splitRecordBytes(data []byte) ([]byte, []byte, error) {
+    length, n := binary.Uvarint(data)
+    if n <= 0 || length > uint64(len(data) - n) {
+        return nil, nil, ErrInvalidFormat
+    }
+    data = data[n:]
+    return data[:length], data[length:], nil
+}
+
+// encodeRecord encodes the log record of the operation into the buffer of the tree
+func (tree *DurableRbTree) encodeRecord(op byte, key RbKey, value interface{}) error {
+    buf := append(tree.buf[:0], make([]byte, durableRecordHeaderSize)...)
+    buf, err := appendTaggedKey(append(buf, op), key)
+    if err != nil {
+        return err
+    }
+
+    if op == durableInsertOp {
+        var data []byte
+        if data, err = tree.tree.getValueCodec().EncodeValue(nil, value); err != nil {
+            return err
+        }
+        buf = binary.AppendUvarint(buf, uint64(len(data)))
+        buf = append(buf, data...)
+    }
+
+    payload := buf[durableRecordHeaderSize:]
+    if len(payload) > durableMaxRecordSize {
+        return ErrInvalidFormat
+    }
+    binary.LittleEndian.PutUint32(buf, uint32(len(payload)))
+    binary.LittleEndian.PutUint32(buf[4:], crc32.ChecksumIEEE(payload))
+    tree.buf = buf
+    return nil
+}
+
+// append writes the encoded record to the log, fsyncs the log according to the sync policy
+// and takes a checkpoint if needed. A partially written record is truncated.
+func (tree *DurableRbTree) append() error {
+    if _, err := tree.log.Write(tree.buf); err != nil {
+        if tree.log.Truncate(tree.offset) == nil {
+            tree.log.Seek(tree.offset, io.SeekStart)
+        }
+        return err
+    }
+    tree.offset += int64(len(tree.buf))
+    tree.records++
+
+    switch tree.options.SyncPolicy {
+    case SyncAlways:
+        if err := tree.sync(); err != nil {
+            return err
+        }
+    case SyncInterval:
+        if time.Since(tree.lastSync) >= tree.options.SyncInterval {
+            if err := tree.sync(); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// checkpointIfNeeded takes a checkpoint if the count of the log records or
+// the time since the last checkpoint reached their limits, the failure is kept for CheckpointErr
+func (tree *DurableRbTree) checkpointIfNeeded() {
+    if (tree.options.CheckpointRecords > 0 && tree.records >= tree.options.CheckpointRecords) ||
+        (tree.options.CheckpointInterval > 0 && time.Since(tree.lastCheckpoint) >= tree.options.CheckpointInterval) {
+        tree.checkpointErr = tree.checkpoint()
+    }
+}
+
+// CheckpointErr returns the error of the last checkpoint triggered by Insert or Delete,
+// returns nil if that checkpoint succeeded or a later Checkpoint call succeeded.
+// The modifications stay durable in the log while the checkpoints fail.
+func (tree *DurableRbTree) CheckpointErr() error {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.checkpointErr
+}
+
+// Insert appends the insert record to the log and inserts the given key and value into the tree,
+// the tree is not changed if the record cannot be written. Returns ErrKeyTypeMismatch without writing
+// if the key type differs from the key type of the tree. Returns nil once the record is written,
+// even if the checkpoint it triggers fails.
+func (tree *DurableRbTree) Insert(key RbKey, value interface{}) error {
+    if key == nil {
+        return ArgumentNilError("key")
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    if tree.log == nil {
+        return ErrTreeClosed
+    }
+    if err := tree.tree.checkKeyType(key); err != nil {
+        return err
+    }
+    if err := tree.encodeRecord(durableInsertOp, key, value); err != nil {
+        return err
+    }
+    if err := tree.append(); err != nil {
+        return err
+    }
+    tree.tree.Insert(key, value)
+    tree.checkpointIfNeeded()
+    return nil
+}
+
+// Delete appends the delete record to the log and deletes the given key from the tree,
+// nothing is written if the key does not exist. Returns ErrKeyTypeMismatch without writing
+// if the key type differs from the key type of the tree. Returns nil once the record is written,
+// even if the checkpoint it triggers fails.
+func (tree *DurableRbTree) Delete(key RbKey) error {
+    if key == nil {
+        return ArgumentNilError("key")
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    if tree.log == nil {
+        return ErrTreeClosed
+    }
+    if err := tree.tree.checkKeyType(key); err != nil {
+        return err
+    }
+    if !tree.tree.Exists(key) {
+        return nil
+    }
+    if err := tree.encodeRecord(durableDeleteOp, key, nil); err != nil {
+        return err
+    }
+    if err := tree.append(); err != nil {
+        return err
+    }
+    tree.tree.Delete(key)
+    tree.checkpointIfNeeded()
+    return nil
+}
+
+// Sync fsyncs the log
+func (tree *DurableRbTree) Sync() error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    if tree.log == nil {
+        return ErrTreeClosed
+    }
+    return tree.sync()
+}
+
+// sync fsyncs the log
+func (tree *DurableRbTree) sync() error {
+    if err := tree.log.Sync(); err != nil {
+        return err
+    }
+    tree.lastSync = time.Now()
+    return nil
+}
+
+// Checkpoint writes a full snapshot of the tree and empties the log
+func (tree *DurableRbTree) Checkpoint() error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    if tree.log == nil {
+        return ErrTreeClosed
+    }
+    tree.checkpointErr = tree.checkpoint()
+    return tree.checkpointErr
+}
+
+// checkpoint writes the snapshot into a temporary file and replaces the last snapshot with it,
+// then empties the log. Replaying the log on the new snapshot after a crash before emptying the log
+// gives the same items, as every record sets the final state of its key.
+func (tree *DurableRbTree) checkpoint() error {
+    path := filepath.Join(tree.dir, durableSnapshotFile)
+    file, err := os.Create(path + ".tmp")
+    if err != nil {
+        return err
+    }
+
+    if _, err = tree.tree.WriteTo(file); err == nil {
+        err = file.Sync()
+    }
+    if closeErr := file.Close(); err == nil {
+        err = closeErr
+    }
+    if err == nil {
+        err = os.Rename(path + ".tmp", path)
+    }
+    if err != nil {
+        os.Remove(path + ".tmp")
+        return err
+    }
+    if err = syncDir(tree.dir); err != nil {
+        return err
+    }
+
+    if err = tree.log.Truncate(0); err != nil {
+        return err
+    }
+    if _, err = tree.log.Seek(0, io.SeekStart); err != nil {
+        return err
+    }
+    tree.offset = 0
+    tree.records = 0
+    tree.lastCheckpoint = time.Now()
+    return tree.sync()
+}
+
+// syncDir fsyncs the directory to persist the renamed files
+func syncDir(dir string) error {
+    file, err := os.Open(dir)
+    if err != nil {
+        return err
+    }
+    defer file.Close()
+    return file.Sync()
+}
+
+// Close fsyncs and closes the log, the tree cannot be modified after closing
+func (tree *DurableRbTree) Close() error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    if tree.log == nil {
+        return ErrTreeClosed
+    }
+    err := tree.log.Sync()
+    if closeErr := tree.log.Close(); err == nil {
+        err = closeErr
+    }
+    tree.log = nil
+    return err
+}
+
+// Count returns if count of the nodes stored.
+func (tree *DurableRbTree) Count() int {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Count()
+}
+
+// IsEmpty returns if the tree has any node.
+func (tree *DurableRbTree) IsEmpty() bool {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.IsEmpty()
+}
+
+// Min returns the smallest key in the tree.
+func (tree *DurableRbTree) Min() (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Min()
+}
+
+// Max returns the largest key in the tree.
+func (tree *DurableRbTree) Max() (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Max()
+}
+
+// Floor returns the largest key in the tree less than or equal to key
+func (tree *DurableRbTree) Floor(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Floor(key)
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to key
+func (tree *DurableRbTree) Ceiling(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Ceiling(key)
+}
+
+// Get returns the stored value if key found and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *DurableRbTree) Get(key RbKey) (interface{}, bool) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Get(key)
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *DurableRbTree) Exists(key RbKey) bool {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Exists(key)
+}
+
+// Snapshot returns a point-in-time view of the tree in O(1)
+func (tree *DurableRbTree) Snapshot() *PersistentRbTree {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.Snapshot()
+}
+
+// All returns a sequence of all items of the tree in ascending order
+// holding a read lock on the tree until the loop ends
+func (tree *DurableRbTree) All() iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.All()
+    })
+}
+
+// Range returns a sequence of the items of the tree in ascending order that the key of the item
+// is greater or equal to loKey and less or equal to hiKey holding a read lock on the tree until the loop ends
+func (tree *DurableRbTree) Range(loKey RbKey, hiKey RbKey) iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Range(loKey, hiKey)
+    })
+}
+
@@ -0,0 +1,216 @@
+package rbt
+
+import (
+    "math/rand"
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+// reopen closes the durable tree and opens it again from its directory
+func reopen(t *testing.T, tree *DurableRbTree, options *DurableOptions) *DurableRbTree {
+    if err := tree.Close(); err != nil {
+        t.Fatal(err)
+    }
+    result, err := OpenDurableRbTree(tree.dir, options)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return result
+}
+
+// checkDurable validates that the durable tree stores exactly the expected items
+func checkDurable(t *testing.T, tree *DurableRbTree, expected map[int]int) {
+    checkRbTree(t, tree.tree.root)
+    if tree.Count() != len(expected) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(expected))
+    }
+    for k, v := range expected {
+        if value, ok := tree.Get(newKey(IntKey(k))); !ok || value != v {
+            t.Fatalf("Get(%d) = %v, %v, want %d", k, value, ok, v)
+        }
+    }
+}
+
+func TestDurableRbTree(t *testing.T) {
+    dir := t.TempDir()
+    options := &DurableOptions{SyncPolicy: SyncNever, CheckpointRecords: 300}
+
+    tree, err := OpenDurableRbTree(dir, options)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    rnd := rand.New(rand.NewSource(4))
+    items := make(map[int]int)
+    for i := 0; i < 1000; i++ {
+        k := rnd.Intn(200)
+        if rnd.Intn(4) == 0 {
+            if err = tree.Delete(newKey(IntKey(k))); err != nil {
+                t.Fatal(err)
+            }
+            delete(items, k)
+        } else {
+            if err = tree.Insert(newKey(IntKey(k)), i); err != nil {
+                t.Fatal(err)
+            }
+            items[k] = i
+        }
+    }
+    if tree.records >= options.CheckpointRecords {
+        t.Fatalf("log has %d records, checkpoint expected after %d", tree.records, options.CheckpointRecords)
+    }
+    if _, err = os.Stat(filepath.Join(dir, durableSnapshotFile)); err != nil {
+        t.Fatalf("no snapshot written: %v", err)
+    }
+
+    tree = reopen(t, tree, options)
+    checkDurable(t, tree, items)
+
+    if err = tree.Checkpoint(); err != nil {
+        t.Fatal(err)
+    }
+    tree = reopen(t, tree, nil)
+    checkDurable(t, tree, items)
+
+    if err = tree.Insert(newKey(IntKey(1)), struct{}{}); err != ErrValueTypeNotSupported {
+        t.Fatalf("Insert of unsupported value returned %v, want %v", err, ErrValueTypeNotSupported)
+    }
+    if err = tree.Close(); err != nil {
+        t.Fatal(err)
+    }
+    if err = tree.Insert(newKey(IntKey(1)), 1); err != ErrTreeClosed {
+        t.Fatalf("Insert after Close returned %v, want %v", err, ErrTreeClosed)
+    }
+}
+
+func TestDurableTornTail(t *testing.T) {
+    dir := t.TempDir()
+    tree, err := OpenDurableRbTree(dir, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    items := make(map[int]int)
+    for i := 0; i < 10; i++ {
+        tree.Insert(newKey(IntKey(i)), i * 2)
+        items[i] = i * 2
+    }
+    size := tree.offset
+    tree.Insert(newKey(IntKey(100)), 100)
+    tree.Close()
+
+    path := filepath.Join(dir, durableLogFile)
+    data, _ := os.ReadFile(path)
+    for cut := size; cut < int64(len(data)); cut++ {
+        if err = os.WriteFile(path, data[:cut], 0644); err != nil {
+            t.Fatal(err)
+        }
+        if tree, err = OpenDurableRbTree(dir, nil); err != nil {
+            t.Fatal(err)
+        }
+        checkDurable(t, tree, items)
+        if tree.offset != size {
+            t.Fatalf("torn tail not truncated: offset %d, want %d", tree.offset, size)
+        }
+        tree.Close()
+    }
+
+    corrupted := append([]byte{}, data...)
+    corrupted[len(corrupted) - 1] ^= 0xff
+    os.WriteFile(path, corrupted, 0644)
+    if tree, err = OpenDurableRbTree(dir, nil); err != nil {
+        t.Fatal(err)
+    }
+    checkDurable(t, tree, items)
+
+    tree.Insert(newKey(IntKey(50)), 50)
+    items[50] = 50
+    tree = reopen(t, tree, nil)
+    checkDurable(t, tree, items)
+    tree.Close()
+}
+
+func TestDurableCheckpointFailure(t *testing.T) {
+    dir := t.TempDir()
+    options := &DurableOptions{SyncPolicy: SyncNever, CheckpointRecords: 2}
+    tree, err := OpenDurableRbTree(dir, options)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    // a non-empty directory in place of the temporary snapshot file fails the checkpoints
+    blocker := filepath.Join(dir, durableSnapshotFile + ".tmp")
+    if err = os.MkdirAll(filepath.Join(blocker, "x"), 0755); err != nil {
+        t.Fatal(err)
+    }
+
+    items := make(map[int]int)
+    for i := 0; i < 5; i++ {
+        if err = tree.Insert(newKey(IntKey(i)), i); err != nil {
+            t.Fatalf("Insert(%d) with a failing checkpoint returned %v", i, err)
+        }
+        items[i] = i
+    }
+    if err = tree.Delete(newKey(IntKey(0))); err != nil {
+        t.Fatalf("Delete(0) with a failing checkpoint returned %v", err)
+    }
+    delete(items, 0)
+    if tree.CheckpointErr() == nil {
+        t.Fatalf("CheckpointErr() = nil after failed checkpoints")
+    }
+    if err = tree.Checkpoint(); err == nil {
+        t.Fatalf("Checkpoint() succeeded with a blocked snapshot file")
+    }
+    checkDurable(t, tree, items)
+
+    if err = os.RemoveAll(blocker); err != nil {
+        t.Fatal(err)
+    }
+    if err = tree.Checkpoint(); err != nil {
+        t.Fatal(err)
+    }
+    if tree.CheckpointErr() != nil {
+        t.Fatalf("CheckpointErr() = %v after a successful checkpoint", tree.CheckpointErr())
+    }
+    tree = reopen(t, tree, options)
+    checkDurable(t, tree, items)
+    tree.Close()
+}
+
+func TestDurableKeyTypeMismatch(t *testing.T) {
+    dir := t.TempDir()
+    tree, err := OpenDurableRbTree(dir, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    items := map[int]int{1: 1, 2: 2}
+    for k, v := range items {
+        tree.Insert(newKey(IntKey(k)), v)
+    }
+    size := tree.offset
+    if err = tree.Insert(newKey(StringKey("x")), 3); err != ErrKeyTypeMismatch {
+        t.Fatalf("Insert of *StringKey returned %v, want %v", err, ErrKeyTypeMismatch)
+    }
+    if err = tree.Delete(newKey(StringKey("x"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("Delete of *StringKey returned %v, want %v", err, ErrKeyTypeMismatch)
+    }
+    if tree.offset != size {
+        t.Fatalf("rejected records written to the log: offset %d, want %d", tree.offset, size)
+    }
+    tree = reopen(t, tree, nil)
+    checkDurable(t, tree, items)
+
+    // a mismatched record already in the log fails the opening instead of panicking
+    if err = tree.encodeRecord(durableInsertOp, newKey(StringKey("x")), 3); err != nil {
+        t.Fatal(err)
+    }
+    if err = tree.append(); err != nil {
+        t.Fatal(err)
+    }
+    tree.Close()
+    if _, err = OpenDurableRbTree(dir, nil); err != ErrKeyTypeMismatch {
+        t.Fatalf("OpenDurableRbTree of a mismatched record returned %v, want %v", err, ErrKeyTypeMismatch)
+    }
+}
@@ -0,0 +1,247 @@
+package rbt
+
+import (
+    "bufio"
+    "bytes"
+    "encoding/binary"
+    "hash"
+    "hash/crc32"
+    "io"
+)
+
+// binaryMagic is written at the start of the binary format of a tree
+var binaryMagic = [4]byte{'R', 'B', 'T', 'S'}
+
+// binaryFormatVersion is the current version of the binary format
+const binaryFormatVersion byte = 1
+
+// SetValueCodec sets the codec used for encoding and decoding the values of the tree,
+// DefaultValueCodec is used if the codec is nil
+func (tree *RbTree) SetValueCodec(codec ValueCodec) {
+    tree.valueCodec = codec
+}
+
+// getValueCodec returns the value codec of the tree
+func (tree *RbTree) getValueCodec() ValueCodec {
+    if tree.valueCodec == nil {
+        return DefaultValueCodec
+    }
+    return tree.valueCodec
+}
+
+// WriteTo writes the items of the tree to the writer in the versioned binary format
+// and returns the count of the bytes written.
+// The keys are encoded with the codecs registered for their types and the values with the value codec of the tree.
+//
+// The format starts with the magic "RBTS", the format version byte and the uvarint count of the items,
+// continues with the items in ascending order, each as the uvarint key tag, the uvarint length and
+// the bytes of the key and the uvarint length and the bytes of the value,
+// and ends with the little-endian CRC-32 (IEEE) checksum of all the preceding bytes.
+func (tree *RbTree) WriteTo(w io.Writer) (int64, error) {
+    if w == nil {
+        return 0, ArgumentNilError("w")
+    }
+
+    writer := bufio.NewWriter(w)
+    crc := crc32.NewIEEE()
+    var n int64
+
+    write := func(data []byte) {
+        crc.Write(data)
+        m, _ := writer.Write(data)
+        n += int64(m)
+    }
+
+    buf := append([]byte{}, binaryMagic[:]...)
+    buf = append(buf, binaryFormatVersion)
+    buf = binary.AppendUvarint(buf, uint64(tree.count))
+    write(buf)
+
+    valueCodec := tree.getValueCodec()
+    var data []byte
+    var err error
+
+    for key, value := range tree.All() {
+        if buf, err = appendTaggedKey(buf[:0], key); err != nil {
+            return n, err
+        }
+
+        if data, err = valueCodec.EncodeValue(data[:0], value); err != nil {
+            return n, err
+        }
+        buf = binary.AppendUvarint(buf, uint64(len(data)))
+        buf = append(buf, data...)
+        write(buf)
+    }
+
+    write(binary.LittleEndian.AppendUint32(buf[:0], crc.Sum32()))
+    return n, writer.Flush()
+}
+
+// ReadFrom replaces the items of the tree with the items read from the reader in the binary format
+// written by WriteTo and returns the count of the bytes read. The tree is built in linear time.
+// The tree is not changed if an error is returned.
+// If the reader does not implement io.ByteReader, it is buffered and may be read beyond the end of the tree data.
+func (tree *RbTree) ReadFrom(r io.Reader) (int64, error) {
+    if r == nil {
+        return 0, ArgumentNilError("r")
+    }
+
+    reader := newBinaryReader(r)
+    keys, values, err := reader.readItems(tree.getValueCodec())
+    if err != nil {
+        if err == io.EOF {
+            err = io.ErrUnexpectedEOF
+        }
+        return reader.n, err
+    }
+
+    err = tree.BuildFromSorted(func(yield func(RbKey, interface{}) bool) {
+        for i, key := range keys {
+            if !yield(key, values[i]) {
+                return
+            }
+        }
+    })
+    return reader.n, err
+}
+
+// byteReader interface is the reader used for reading the binary format
+type byteReader interface {
+    io.Reader
+    io.ByteReader
+}
+
+// binaryReader structure reads the binary format calculating its checksum and the count of the bytes read
+type binaryReader struct {
+    src byteReader
+    crc hash.Hash32
+    n int64
+}
+
+// newBinaryReader creates a new binaryReader reading from r
+func newBinaryReader(r io.Reader) *binaryReader {
+    src, ok := r.(byteReader)
+    if !ok {
+        src = bufio.NewReader(r)
+    }
+    return &binaryReader{
+        src: src,
+        crc: crc32.NewIEEE(),
+    }
+}
+
+// Read reads up to len(p) bytes into p
+func (reader *binaryReader) Read(p []byte) (int, error) {
+    n, err := reader.src.Read(p)
+    reader.crc.Write(p[:n])
+    reader.n += int64(n)
+    return n, err
+}
+
+// ReadByte reads a single byte
+func (reader *binaryReader) ReadByte() (byte, error) {
+    b, err := reader.src.ReadByte()
+    if err == nil {
+        reader.crc.Write([]byte{b})
+        reader.n++
+    }
+    return b, err
+}
+
+// readUvarint reads an uvarint
+func (reader *binaryReader) readUvarint() (uint64, error) {
+    v, err := binary.ReadUvarint(reader)
+    if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+        return 0, ErrInvalidFormat
+    }
+    return v, err
+}
+
+// readBytes reads a byte slice prefixed with its uvarint length
+func (reader *binaryReader) readBytes() ([]byte, error) {
+    length, err := reader.readUvarint()
+    if err != nil {
+        return nil, err
+    }
+
+    var buf bytes.Buffer
+    if _, err = buf.ReadFrom(io.LimitReader(reader, int64(length))); err != nil {
+        return nil, err
+    }
+    if uint64(buf.Len()) != length {
+        return nil, io.ErrUnexpectedEOF
+    }
+    return buf.Bytes(), nil
+}
+
+// readItems reads the header, the items and the checksum of the binary format
+func (reader *binaryReader) readItems(valueCodec ValueCodec) ([]RbKey, []interface{}, error) {
+    header := make([]byte, len(binaryMagic) + 1)
+    if _, err := io.ReadFull(reader, header); err != nil {
+        return nil, nil, err
+    }
+    if !bytes.Equal(header[:len(binaryMagic)], binaryMagic[:]) {
+        return nil, nil, ErrInvalidFormat
+    }
+    if header[len(binaryMagic)] != binaryFormatVersion {
+        return nil, nil, ErrUnsupportedFormatVersion
+    }
+
+    count, err := reader.readUvarint()
+    if err != nil {
+        return nil, nil, err
+    }
+
+    capacity := count
+    if capacity > 1 << 16 {
+        capacity = 1 << 16
+    }
+    keys := make([]RbKey, 0, capacity)
+    values := make([]interface{}, 0, capacity)
+
+    for i := uint64(0); i < count; i++ {
+        tag, err := reader.readUvarint()
+        if err != nil {
+            return nil, nil, err
+        }
+        if tag > uint64(^uint32(0)) {
+            return nil, nil, ErrInvalidFormat
+        }
+
+        codec, err := keyCodecByTag(uint32(tag))
+        if err != nil {
+            return nil, nil, err
+        }
+
+        data, err := reader.readBytes()
+        if err != nil {
+            return nil, nil, err
+        }
+        key, err := codec.DecodeKey(data)
+        if err != nil {
+            return nil, nil, err
+        }
+
+        if data, err = reader.readBytes(); err != nil {
+            return nil, nil, err
+        }
+        value, err := valueCodec.DecodeValue(data)
+        if err != nil {
+            return nil, nil, err
+        }
+
+        keys = append(keys, key)
+        values = append(values, value)
+    }
+
+    sum := reader.crc.Sum32()
+    checksum := make([]byte, 4)
+    if _, err := io.ReadFull(reader, checksum); err != nil {
+        return nil, nil, err
+    }
+    if binary.LittleEndian.Uint32(checksum) != sum {
+        return nil, nil, ErrInvalidFormat
+    }
+    return keys, values, nil
+}
@@ -0,0 +1,202 @@
+package rbt
+
+import (
+    "bytes"
+    "io"
+    "math"
+    "testing"
+)
+
+// pointKey is a key type registered with pointKeyCodec
+type pointKey struct {
+    x, y int
+}
+
+func (pkey *pointKey) ComparedTo(key RbKey) KeyComparison {
+    other := key.(*pointKey)
+    switch {
+    case pkey.x < other.x || (pkey.x == other.x && pkey.y < other.y):
+        return KeyIsLess
+    case pkey.x > other.x || (pkey.x == other.x && pkey.y > other.y):
+        return KeyIsGreater
+    default:
+        return KeysAreEqual
+    }
+}
+
+// unregisteredKey is a key type never registered with a codec
+type unregisteredKey struct {
+    NilKey
+}
+
+// roundTrip writes the tree and reads it back into a new tree
+func roundTrip(t *testing.T, tree *RbTree) *RbTree {
+    var buf bytes.Buffer
+    n, err := tree.WriteTo(&buf)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if n != int64(buf.Len()) {
+        t.Fatalf("WriteTo returned %d, wrote %d bytes", n, buf.Len())
+    }
+
+    result := NewRbTree()
+    result.SetValueCodec(tree.valueCodec)
+    size := int64(buf.Len())
+    if n, err = result.ReadFrom(&buf); err != nil {
+        t.Fatal(err)
+    }
+    if n != size {
+        t.Fatalf("ReadFrom returned %d, want %d", n, size)
+    }
+    checkRbTree(t, result.root)
+    return result
+}
+
+// checkSameItems validates that both trees have the same keys and values
+func checkSameItems(t *testing.T, tree *RbTree, expected *RbTree) {
+    if tree.Count() != expected.Count() {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), expected.Count())
+    }
+    for key, value := range expected.All() {
+        v, ok := tree.Get(key)
+        if !ok {
+            t.Fatalf("key %v not found", key)
+        }
+        if b, isBytes := value.([]byte); isBytes {
+            if !bytes.Equal(v.([]byte), b) {
+                t.Fatalf("Get(%v) = %v, want %v", key, v, value)
+            }
+        } else if v != value {
+            t.Fatalf("Get(%v) = %v (%T), want %v (%T)", key, v, v, value, value)
+        }
+    }
+}
+
+func TestBinaryKeyTypes(t *testing.T) {
+    keys := [][]RbKey{
+        {&NilKey{}},
+        {newKey(BoolKey(false)), newKey(BoolKey(true))},
+        {newKey(ByteKey(0)), newKey(ByteKey(255))},
+        {newKey(IntKey(-1 << 40)), newKey(IntKey(0)), newKey(IntKey(7))},
+        {newKey(Int8Key(math.MinInt8)), newKey(Int8Key(math.MaxInt8))},
+        {newKey(Int16Key(math.MinInt16)), newKey(Int16Key(math.MaxInt16))},
+        {newKey(Int32Key(math.MinInt32)), newKey(Int32Key(math.MaxInt32))},
+        {newKey(Int64Key(math.MinInt64)), newKey(Int64Key(math.MaxInt64))},
+        {newKey(UintKey(0)), newKey(UintKey(1 << 40))},
+        {newKey(Uint8Key(1)), newKey(Uint8Key(math.MaxUint8))},
+        {newKey(Uint16Key(1)), newKey(Uint16Key(math.MaxUint16))},
+        {newKey(Uint32Key(1)), newKey(Uint32Key(math.MaxUint32))},
+        {newKey(Uint64Key(1)), newKey(Uint64Key(math.MaxUint64))},
+        {newKey(Float32Key(-1.5)), newKey(Float32Key(math.MaxFloat32))},
+        {newKey(Float64Key(-math.MaxFloat64)), newKey(Float64Key(math.Pi))},
+        {newKey(StringKey("")), newKey(StringKey("abc")), newKey(StringKey("ü"))},
+    }
+
+    for _, list := range keys {
+        tree := NewRbTree()
+        for i, key := range list {
+            tree.Insert(key, i)
+        }
+        checkSameItems(t, roundTrip(t, tree), tree)
+    }
+}
+
+func TestBinaryValues(t *testing.T) {
+    values := []interface{}{
+        nil, true, false, -5, int8(-8), int16(16), int32(-32), int64(math.MinInt64),
+        uint(5), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64),
+        float32(1.25), math.Inf(-1), "text", []byte{1, 2, 3},
+    }
+
+    tree := NewRbTree()
+    for i, value := range values {
+        tree.Insert(newKey(IntKey(i)), value)
+    }
+    checkSameItems(t, roundTrip(t, tree), tree)
+
+    tree.Insert(newKey(IntKey(-1)), struct{}{})
+    if _, err := tree.WriteTo(io.Discard); err != ErrValueTypeNotSupported {
+        t.Fatalf("WriteTo returned %v, want %v", err, ErrValueTypeNotSupported)
+    }
+}
+
+func TestBinaryErrors(t *testing.T) {
+    tree := NewRbTree()
+    for i := 0; i < 100; i++ {
+        tree.Insert(newKey(IntKey(i)), i * i)
+    }
+
+    var buf bytes.Buffer
+    tree.WriteTo(&buf)
+    data := buf.Bytes()
+
+    target := NewRbTree()
+    target.Insert(newKey(IntKey(-1)), -1)
+
+    for i := 0; i < len(data); i++ {
+        if _, err := target.ReadFrom(bytes.NewReader(data[:i])); err != io.ErrUnexpectedEOF && err != ErrInvalidFormat {
+            t.Fatalf("ReadFrom of %d bytes returned %v", i, err)
+        }
+
+        corrupted := append([]byte{}, data...)
+        corrupted[i] ^= 0x10
+        if _, err := target.ReadFrom(bytes.NewReader(corrupted)); err == nil {
+            t.Fatalf("ReadFrom of data corrupted at %d succeeded", i)
+        }
+    }
+    if target.Count() != 1 || !target.Exists(newKey(IntKey(-1))) {
+        t.Fatalf("tree changed by failed ReadFrom")
+    }
+
+    versioned := append([]byte{}, data...)
+    versioned[len(binaryMagic)] = binaryFormatVersion + 1
+    if _, err := target.ReadFrom(bytes.NewReader(versioned)); err != ErrUnsupportedFormatVersion {
+        t.Fatalf("ReadFrom of unknown version returned %v, want %v", err, ErrUnsupportedFormatVersion)
+    }
+
+    unregistered := NewRbTree()
+    unregistered.Insert(&unregisteredKey{}, "a")
+    if _, err := unregistered.WriteTo(io.Discard); err != ErrKeyCodecNotFound {
+        t.Fatalf("WriteTo of unregistered key returned %v, want %v", err, ErrKeyCodecNotFound)
+    }
+}
+
+// pointKeyCodec encodes pointKey as two bytes
+type pointKeyCodec struct {}
+
+func (pointKeyCodec) EncodeKey(buf []byte, key RbKey) ([]byte, error) {
+    point := key.(*pointKey)
+    buf = append(buf, byte(point.x), byte(point.y))
+    return buf, nil
+}
+
+func (pointKeyCodec) DecodeKey(data []byte) (RbKey, error) {
+    if len(data) != 2 {
+        return nil, ErrInvalidFormat
+    }
+    return &pointKey{int(data[0]), int(data[1])}, nil
+}
+
+func TestRegisterKeyCodec(t *testing.T) {
+    if err := RegisterKeyCodec(1, &pointKey{}, pointKeyCodec{}); err != ErrKeyCodecExists {
+        t.Fatalf("RegisterKeyCodec with reserved tag returned %v, want %v", err, ErrKeyCodecExists)
+    }
+    if err := RegisterKeyCodec(MinUserKeyTag, &pointKey{}, pointKeyCodec{}); err != nil {
+        t.Fatal(err)
+    }
+    if err := RegisterKeyCodec(MinUserKeyTag + 1, &pointKey{}, pointKeyCodec{}); err != ErrKeyCodecExists {
+        t.Fatalf("RegisterKeyCodec of registered type returned %v, want %v", err, ErrKeyCodecExists)
+    }
+
+    tree := NewRbTree()
+    for i := 0; i < 10; i++ {
+        tree.Insert(&pointKey{i % 3, i}, i)
+    }
+    checkSameItems(t, roundTrip(t, tree), tree)
+}
+
+// newKey returns the address of a copy of the built-in key
+func newKey[T any, P interface { *T; RbKey }](value T) RbKey {
+    return P(&value)
+}
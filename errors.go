@@ -20,6 +20,32 @@ const (
     ErrNoIteratorClosed
     // ErrNoIteratorUninitialized is used if the iterator is uninitialized
     ErrNoIteratorUninitialized
+    // ErrNoArgumentLengthMismatch is used if the lengths of the function parameters do not match
+    ErrNoArgumentLengthMismatch
+    // ErrNoKeysNotSorted is used if the keys are not sorted in ascending order
+    ErrNoKeysNotSorted
+    // ErrNoDuplicateKey is used if a key is given more than once
+    ErrNoDuplicateKey
+    // ErrNoKeyRangesOverlap is used if the key ranges of the trees overlap
+    ErrNoKeyRangesOverlap
+    // ErrNoKeyCodecNotFound is used if no key codec is registered for the key type or tag
+    ErrNoKeyCodecNotFound
+    // ErrNoKeyCodecExists is used if a key codec is already registered for the key type or tag
+    ErrNoKeyCodecExists
+    // ErrNoValueTypeNotSupported is used if the value codec cannot encode the value type
+    ErrNoValueTypeNotSupported
+    // ErrNoInvalidFormat is used if the encoded data is invalid or corrupted
+    ErrNoInvalidFormat
+    // ErrNoUnsupportedFormatVersion is used if the version of the encoded data is not supported
+    ErrNoUnsupportedFormatVersion
+    // ErrNoTreeClosed is used if the tree is already closed
+    ErrNoTreeClosed
+    // ErrNoKeyTypeMismatch is used if the key type does not match the key type of the tree
+    ErrNoKeyTypeMismatch
+    // ErrNoIterationPanicked is used if the iteration callback panics with a value other than an error
+    ErrNoIterationPanicked
+    // ErrNoCapacityExceeded is used if the tree cannot hold more items
+    ErrNoCapacityExceeded
 )
 
 var (
@@ -33,6 +59,33 @@ var (
     ErrIteratorClosed = NewError(ErrNoIteratorClosed)
     // ErrIteratorUninitialized used if the iterator is uninitialized
     ErrIteratorUninitialized = NewError(ErrNoIteratorUninitialized)
+    // ErrArgumentLengthMismatch used if the lengths of the function parameters do not match
+    ErrArgumentLengthMismatch = NewError(ErrNoArgumentLengthMismatch)
+    // ErrKeysNotSorted used if the keys are not sorted in ascending order
+    ErrKeysNotSorted = NewError(ErrNoKeysNotSorted)
+    // ErrDuplicateKey used if a key is given more than once
+    ErrDuplicateKey = NewError(ErrNoDuplicateKey)
+    // ErrKeyRangesOverlap used if the key ranges of the trees overlap
+    ErrKeyRangesOverlap = NewError(ErrNoKeyRangesOverlap)
+    // ErrKeyCodecNotFound used if no key codec is registered for the key type or tag
+    ErrKeyCodecNotFound = NewError(ErrNoKeyCodecNotFound)
+    // ErrKeyCodecExists used if a key codec is already registered for the key type or tag
+    ErrKeyCodecExists = NewError(ErrNoKeyCodecExists)
+    // ErrValueTypeNotSupported used if the value codec cannot encode the value type
+    ErrValueTypeNotSupported = NewError(ErrNoValueTypeNotSupported)
+    // ErrInvalidFormat used if the encoded data is invalid or corrupted
+    ErrInvalidFormat = NewError(ErrNoInvalidFormat)
+    // ErrUnsupportedFormatVersion used if the version of the encoded data is not supported
+    ErrUnsupportedFormatVersion = NewError(ErrNoUnsupportedFormatVersion)
+    // ErrTreeClosed used if the tree is already closed
+    ErrTreeClosed = NewError(ErrNoTreeClosed)
+    // ErrKeyTypeMismatch used if the key type does not match the key type of the tree
+    ErrKeyTypeMismatch = NewError(ErrNoKeyTypeMismatch)
+    // ErrIterationPanicked used if the iteration callback panics with a value other than an error,
+    // the returned errors carry the panic value in their message and match ErrIterationPanicked with errors.Is
+    ErrIterationPanicked = NewErrorDetailed(ErrNoIterationPanicked, "Iteration panicked.")
+    // ErrCapacityExceeded used if the tree cannot hold more items
+    ErrCapacityExceeded = NewError(ErrNoCapacityExceeded)
 )
 
 var errorStr = map[ErrNo]string {
@@ -42,6 +95,19 @@ var errorStr = map[ErrNo]string {
     ErrNoIteratorAlreadyRunning: "Iterator already running.",
     ErrNoIteratorClosed: "Iteration context closed.",
     ErrNoIteratorUninitialized: "Iteration context uninitialized.",
+    ErrNoArgumentLengthMismatch: "Argument lengths do not match.",
+    ErrNoKeysNotSorted: "Keys are not sorted in ascending order.",
+    ErrNoDuplicateKey: "Duplicate key.",
+    ErrNoKeyRangesOverlap: "Key ranges of the trees overlap.",
+    ErrNoKeyCodecNotFound: "No key codec registered for the key.",
+    ErrNoKeyCodecExists: "Key codec already registered.",
+    ErrNoValueTypeNotSupported: "Value type not supported by the codec.",
+    ErrNoInvalidFormat: "Invalid or corrupted data format.",
+    ErrNoUnsupportedFormatVersion: "Unsupported data format version.",
+    ErrNoTreeClosed: "Tree closed.",
+    ErrNoKeyTypeMismatch: "Key type does not match the key type of the tree.",
+    ErrNoIterationPanicked: "Iteration panicked: %v",
+    ErrNoCapacityExceeded: "Tree capacity exceeded.",
 }
 
 type errorDef struct {
@@ -83,6 +149,15 @@ func ArgumentNilError(arg string) error {
     }
 }
 
+// recoveredError returns the value recovered from a panic as an error,
+// wrapping the values other than errors with ErrNoIterationPanicked error no
+func recoveredError(r interface{}) error {
+    if err, ok := r.(error); ok {
+        return err
+    }
+    return NewErrorDetailed(ErrNoIterationPanicked, fmt.Sprintf(errorStr[ErrNoIterationPanicked], r))
+}
+
 // Error returns the error message
 func (err *errorDef) Error() string {
 	return err.message
@@ -91,4 +166,11 @@ func (err *errorDef) Error() string {
 // ErrorNo returns the error no
 func (err *errorDef) ErrorNo() ErrNo {
 	return err.err
+}
+
+// Is reports if the target is an error with the same error no, so the detailed errors
+// match their sentinel errors with errors.Is
+func (err *errorDef) Is(target error) bool {
+    other, ok := target.(*errorDef)
+    return ok && other.err == err.err
 }
\ No newline at end of file
@@ -3,17 +3,12 @@ package rbt
 // Float64Key is the float64 key for RbKey
 type Float64Key float64
 
-const zeroFloat64Key = Float64Key(0)
-
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (fkey *Float64Key) ComparedTo(key RbKey) KeyComparison {
-    diff := *fkey - *key.(*Float64Key)
-    switch {
-    case diff > zeroFloat64Key:
-        return KeyIsGreater
-    case diff < zeroFloat64Key:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Float64Key); ok {
+        return compareOrdered(*fkey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(fkey)
+    return compareNumericKey(a, key)
+}
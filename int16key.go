@@ -3,15 +3,12 @@ package rbt
 // Int16Key is the int16 key for RbKey
 type Int16Key int16
 
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *Int16Key) ComparedTo(key RbKey) KeyComparison {
-    diff := int16(*ikey - *key.(*Int16Key))
-    switch {
-    case diff > 0:
-        return KeyIsGreater
-    case diff < 0:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Int16Key); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
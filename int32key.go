@@ -3,17 +3,12 @@ package rbt
 // Int32Key is the int32 key for RbKey
 type Int32Key int32
 
-const zeroInt32Key = Int32Key(0)
-
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *Int32Key) ComparedTo(key RbKey) KeyComparison {
-    diff := *ikey - *key.(*Int32Key)
-    switch {
-    case diff > zeroInt32Key:
-        return KeyIsGreater
-    case diff < zeroInt32Key:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Int32Key); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
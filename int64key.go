@@ -3,17 +3,12 @@ package rbt
 // Int64Key is the int64 key for RbKey
 type Int64Key int64
 
-const zeroInt64Key = Int64Key(0)
-
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *Int64Key) ComparedTo(key RbKey) KeyComparison {
-    diff := *ikey - *key.(*Int64Key)
-    switch {
-    case diff > zeroInt64Key:
-        return KeyIsGreater
-    case diff < zeroInt64Key:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Int64Key); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
@@ -0,0 +1,219 @@
+package rbt
+
+import (
+    "iter"
+)
+
+// Interval structure holds the half-open [Start, End) range of an IntervalRbTree item
+type Interval struct {
+    Start RbKey
+    End RbKey
+}
+
+// IntervalRbTree structure is the red-black tree of intervals ordered by their
+// start and end keys. Each node is augmented with the largest end key in its subtree,
+// so the overlapping intervals can be found without walking on the whole tree.
+type IntervalRbTree struct {
+    tree *RbTree
+}
+
+// intervalKey structure is the key of the inner tree ordering the intervals by their start and end keys
+type intervalKey struct {
+    start, end RbKey
+}
+
+// intervalEntry structure is the value of the inner tree augmented with the largest end key in the subtree
+type intervalEntry struct {
+    value interface{}
+    maxEnd RbKey
+}
+
+// NewIntervalRbTree creates a new IntervalRbTree and returns its address
+func NewIntervalRbTree() *IntervalRbTree {
+    return &IntervalRbTree{
+        tree: &RbTree{
+            onInsert: replaceIntervalValue,
+            augmented: true,
+        },
+    }
+}
+
+// replaceIntervalValue is the insert event of the inner tree keeping the entry of an existing interval
+func replaceIntervalValue(key RbKey, oldValue interface{}, newValue interface{}) interface{} {
+    entry := oldValue.(*intervalEntry)
+    entry.value = newValue.(*intervalEntry).value
+    return entry
+}
+
+// ComparedTo compares the interval with the given interval by their start and end keys
+func (ikey *intervalKey) ComparedTo(key RbKey) KeyComparison {
+    other := key.(*intervalKey)
+    if cmp := ikey.start.ComparedTo(other.start); cmp != KeysAreEqual {
+        return cmp
+    }
+    return ikey.end.ComparedTo(other.end)
+}
+
+// augment recalculates the largest end key in the subtree rooted at node
+func (entry *intervalEntry) augment(node *rbNode) {
+    entry.maxEnd = node.key.(*intervalKey).end
+    if node.left != nil && maxEnd(node.left).ComparedTo(entry.maxEnd) == KeyIsGreater {
+        entry.maxEnd = maxEnd(node.left)
+    }
+    if node.right != nil && maxEnd(node.right).ComparedTo(entry.maxEnd) == KeyIsGreater {
+        entry.maxEnd = maxEnd(node.right)
+    }
+}
+
+// maxEnd returns the largest end key in the subtree rooted at node
+func maxEnd(node *rbNode) RbKey {
+    return node.value.(*intervalEntry).maxEnd
+}
+
+// newIntervalKey creates the key of the interval swapping the start and end keys if start is greater than end,
+// returns nil if any of the keys is nil
+func newIntervalKey(start, end RbKey) *intervalKey {
+    if start == nil || end == nil {
+        return nil
+    }
+    if start.ComparedTo(end) == KeyIsGreater {
+        start, end = end, start
+    }
+    return &intervalKey{start: start, end: end}
+}
+
+// Count returns if count of the intervals stored.
+func (tree *IntervalRbTree) Count() int {
+    return tree.tree.Count()
+}
+
+// IsEmpty returns if the tree has any interval.
+func (tree *IntervalRbTree) IsEmpty() bool {
+    return tree.tree.IsEmpty()
+}
+
+// Get returns the stored value if interval found and 'true',
+// otherwise returns 'false' with second return param if interval not found
+func (tree *IntervalRbTree) Get(start, end RbKey) (interface{}, bool) {
+    if key := newIntervalKey(start, end); key != nil {
+        if node := tree.tree.find(key); node != nil {
+            return node.value.(*intervalEntry).value, true
+        }
+    }
+    return nil, false
+}
+
+// Exists returns 'true' if interval found, otherwise returns 'false'
+func (tree *IntervalRbTree) Exists(start, end RbKey) bool {
+    _, ok := tree.Get(start, end)
+    return ok
+}
+
+// Insert inserts the given [start, end) interval and value into the tree,
+// the start and end keys are swapped if start is greater than end
+func (tree *IntervalRbTree) Insert(start, end RbKey, value interface{}) {
+    if key := newIntervalKey(start, end); key != nil {
+        tree.tree.Insert(key, &intervalEntry{value: value, maxEnd: key.end})
+    }
+}
+
+// Delete deletes the given [start, end) interval from the tree
+func (tree *IntervalRbTree) Delete(start, end RbKey) {
+    if key := newIntervalKey(start, end); key != nil && tree.tree.find(key) != nil {
+        tree.tree.Delete(key)
+    }
+}
+
+// intervalWalk structure holds the state of an overlap search on an IntervalRbTree
+type intervalWalk struct {
+    tree *RbTree
+    version uint32
+    lo, hi RbKey
+    hiInclusive bool
+    yield func(Interval, interface{}) bool
+}
+
+// All returns a sequence of all intervals of the tree ordered by their start and end keys
+func (tree *IntervalRbTree) All() iter.Seq2[Interval, interface{}] {
+    return tree.overlapping(nil, nil, false)
+}
+
+// Overlapping returns a sequence of the intervals of the tree overlapping with the [lo, hi) range,
+// which are the intervals starting before hi and ending after lo. The sequence is empty
+// if lo is equal to hi, as the [lo, lo) range is empty, use Stabbing for the intervals containing a point.
+// The sequence panics with ErrEnumeratorModified if the tree gets modified while iterating.
+func (tree *IntervalRbTree) Overlapping(lo, hi RbKey) iter.Seq2[Interval, interface{}] {
+    if lo != nil && hi != nil {
+        switch lo.ComparedTo(hi) {
+        case KeyIsGreater:
+            lo, hi = hi, lo
+        case KeysAreEqual:
+            return func(yield func(Interval, interface{}) bool) {}
+        }
+    }
+    return tree.overlapping(lo, hi, false)
+}
+
+// Stabbing returns a sequence of the intervals of the tree containing the given point.
+// The sequence panics with ErrEnumeratorModified if the tree gets modified while iterating.
+func (tree *IntervalRbTree) Stabbing(point RbKey) iter.Seq2[Interval, interface{}] {
+    return tree.overlapping(point, point, true)
+}
+
+// overlapping creates the sequence of the intervals ending after lo and starting before hi,
+// or at hi if hiInclusive, a nil key leaves that side of the range open
+func (tree *IntervalRbTree) overlapping(lo, hi RbKey, hiInclusive bool) iter.Seq2[Interval, interface{}] {
+    return func(yield func(Interval, interface{}) bool) {
+        walk := &intervalWalk{
+            tree: tree.tree,
+            version: tree.tree.version,
+            lo: lo,
+            hi: hi,
+            hiInclusive: hiInclusive,
+            yield: yield,
+        }
+        walk.walk(tree.tree.root)
+    }
+}
+
+// startsBeforeHi checks if the start key is inside the upper bound of the walk
+func (walk *intervalWalk) startsBeforeHi(start RbKey) bool {
+    if walk.hi == nil {
+        return true
+    }
+    cmp := start.ComparedTo(walk.hi)
+    return cmp == KeyIsLess || (walk.hiInclusive && cmp == KeysAreEqual)
+}
+
+// endsAfterLo checks if the end key is inside the lower bound of the walk
+func (walk *intervalWalk) endsAfterLo(end RbKey) bool {
+    return walk.lo == nil || end.ComparedTo(walk.lo) == KeyIsGreater
+}
+
+// walk visits the overlapping intervals of the subtree rooted at node in ascending order,
+// skipping the subtrees that all intervals end before lo, returns 'false' if the walk stopped
+func (walk *intervalWalk) walk(node *rbNode) bool {
+    for node != nil {
+        if walk.version != walk.tree.version {
+            panic(ErrEnumeratorModified)
+        }
+        if !walk.endsAfterLo(maxEnd(node)) {
+            return true
+        }
+        if !walk.walk(node.left) {
+            return false
+        }
+
+        key := node.key.(*intervalKey)
+        if !walk.startsBeforeHi(key.start) {
+            return true
+        }
+        if walk.endsAfterLo(key.end) {
+            if !walk.yield(Interval{Start: key.start, End: key.end}, node.value.(*intervalEntry).value) {
+                return false
+            }
+        }
+        node = node.right
+    }
+    return true
+}
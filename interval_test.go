@@ -0,0 +1,130 @@
+package rbt
+
+import (
+    "math/rand"
+    "testing"
+)
+
+// checkMaxEnd validates the largest end keys of the subtree rooted at node
+func checkMaxEnd(t *testing.T, node *rbNode) {
+    if node == nil {
+        return
+    }
+    checkMaxEnd(t, node.left)
+    checkMaxEnd(t, node.right)
+
+    entry := node.value.(*intervalEntry)
+    want := *entry
+    want.augment(node)
+    if entry.maxEnd.ComparedTo(want.maxEnd) != KeysAreEqual {
+        t.Fatalf("max end of %v is %v, want %v", node.key, entry.maxEnd, want.maxEnd)
+    }
+}
+
+// checkIntervalTree validates the red-black tree invariants, the ordering and
+// the largest end keys of the interval tree
+func checkIntervalTree(t *testing.T, tree *IntervalRbTree) {
+    checkRbTree(t, tree.tree.root)
+    checkMaxEnd(t, tree.tree.root)
+    checkParents(t, tree.tree.root, nil)
+}
+
+func TestIntervalRbTree(t *testing.T) {
+    rnd := rand.New(rand.NewSource(3))
+    tree := NewIntervalRbTree()
+    items := make(map[[2]int]int)
+
+    for i := 0; i < 5000; i++ {
+        s := rnd.Intn(1000)
+        e := s + 1 + rnd.Intn(50)
+        start, end := IntKey(s), IntKey(e)
+        if rnd.Intn(3) == 0 {
+            tree.Delete(&start, &end)
+            delete(items, [2]int{s, e})
+        } else {
+            tree.Insert(&end, &start, i)
+            items[[2]int{s, e}] = i
+        }
+    }
+
+    checkIntervalTree(t, tree)
+    if tree.Count() != len(items) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(items))
+    }
+    for interval, v := range items {
+        start, end := IntKey(interval[0]), IntKey(interval[1])
+        if value, ok := tree.Get(&start, &end); !ok || value != v {
+            t.Fatalf("Get(%v) = %v, %v, want %d", interval, value, ok, v)
+        }
+    }
+
+    collect := func(seq func(func(Interval, interface{}) bool)) map[[2]int]int {
+        result := make(map[[2]int]int)
+        var last *Interval
+        seq(func(interval Interval, value interface{}) bool {
+            if last != nil && (&intervalKey{start: last.Start, end: last.End}).ComparedTo(&intervalKey{start: interval.Start, end: interval.End}) != KeyIsLess {
+                t.Fatalf("intervals not in ascending order: %v, %v", *last, interval)
+            }
+            last = &Interval{Start: interval.Start, End: interval.End}
+            result[[2]int{int(*interval.Start.(*IntKey)), int(*interval.End.(*IntKey))}] = value.(int)
+            return true
+        })
+        return result
+    }
+    compare := func(name string, got, want map[[2]int]int) {
+        if len(got) != len(want) {
+            t.Fatalf("%s: got %d intervals, want %d", name, len(got), len(want))
+        }
+        for interval, v := range want {
+            if got[interval] != v {
+                t.Fatalf("%s: missing interval %v", name, interval)
+            }
+        }
+    }
+
+    compare("All", collect(tree.All()), items)
+
+    for i := 0; i < 200; i++ {
+        lo := rnd.Intn(1100) - 50
+        hi := lo + rnd.Intn(30)
+        loKey, hiKey := IntKey(lo), IntKey(hi)
+
+        overlapping, stabbing := make(map[[2]int]int), make(map[[2]int]int)
+        for interval, v := range items {
+            if lo < hi && interval[0] < hi && lo < interval[1] {
+                overlapping[interval] = v
+            }
+            if interval[0] <= lo && lo < interval[1] {
+                stabbing[interval] = v
+            }
+        }
+        compare("Overlapping", collect(tree.Overlapping(&hiKey, &loKey)), overlapping)
+        compare("Stabbing", collect(tree.Stabbing(&loKey)), stabbing)
+    }
+
+    for i := 0; i < 50; i++ {
+        p := IntKey(rnd.Intn(1000))
+        for interval := range tree.Overlapping(&p, &p) {
+            t.Fatalf("Overlapping(%d, %d) yielded %v on an empty range", p, p, interval)
+        }
+    }
+
+    point := IntKey(500)
+    count := 0
+    for range tree.Stabbing(&point) {
+        count++
+        break
+    }
+    if count != 1 {
+        t.Fatalf("Stabbing did not stop after break")
+    }
+
+    defer func() {
+        if r := recover(); r != ErrEnumeratorModified {
+            t.Fatalf("modifying while iterating recovered %v, want %v", r, ErrEnumeratorModified)
+        }
+    }()
+    for interval := range tree.All() {
+        tree.Delete(interval.Start, interval.End)
+    }
+}
@@ -3,17 +3,12 @@ package rbt
 // IntKey is the integer key for RbKey
 type IntKey int
 
-const zeroIntKey = IntKey(0)
-
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *IntKey) ComparedTo(key RbKey) KeyComparison {
-    diff := *ikey - *key.(*IntKey)
-    switch {
-    case diff > zeroIntKey:
-        return KeyIsGreater
-    case diff < zeroIntKey:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*IntKey); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
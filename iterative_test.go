@@ -0,0 +1,437 @@
+package rbt
+
+import (
+    "math/rand"
+    "testing"
+)
+
+// walkAllRecursive is the former walk on all items checking the iteration on each frame,
+// used as the baseline of the benchmarks
+func (context *rbIterationContext) walkAllRecursive(node *rbNode) {
+    if node == nil || !context.inWalk() {
+        return
+    }
+    
+    if context.tree == nil || context.version != context.tree.version {
+        panic(ErrEnumeratorModified)
+    }
+    
+    if node.left != nil {
+        context.walkAllRecursive(node.left)
+        if !context.inWalk() {
+            return
+        }
+    }
+    
+    context.incrementCount()
+    context.callback(context, node.key, node.value)
+    if !context.inWalk() {
+        return
+    }
+    
+    if node.right != nil {
+        context.walkAllRecursive(node.right)
+    }    
+}
+
+// walkBetweenRecursive is the former walk between the keys checking the iteration on each frame,
+// used as the baseline of the benchmarks
+func (context *rbIterationContext) walkBetweenRecursive(node *rbNode, loKey RbKey, hiKey RbKey) {
+    if node == nil || !context.inWalk() {
+        return
+    }
+    
+    if context.tree == nil || context.version != context.tree.version {
+        panic(ErrEnumeratorModified)
+    }
+    
+    cmpLo := int8(context.tree.compareKeys(loKey, node.key))
+    if cmpLo < zeroOrEqual {
+        if node.left != nil {
+            context.walkBetweenRecursive(node.left, loKey, hiKey)
+            if !context.inWalk() {
+                return
+            }
+        }
+    } 
+    
+    cmpHi := int8(context.tree.compareKeys(hiKey, node.key))
+    if cmpLo <= zeroOrEqual && cmpHi >= zeroOrEqual {
+        context.incrementCount()
+        context.callback(context, node.key, node.value)
+        if !context.inWalk() {
+            return
+        }
+    } 
+    
+    if cmpHi > zeroOrEqual {
+        if node.right != nil {
+            context.walkBetweenRecursive(node.right, loKey, hiKey)
+        }    
+    }
+}
+
+// insertNodeRecursive is the recursive insert used as the baseline of the benchmarks,
+// maintaining the parent links as the iterative insert does
+func (tree *RbTree) insertNodeRecursive(node *rbNode, key RbKey, value interface{}) *rbNode {
+    if node == nil {
+        tree.count++
+        return tree.newNode(key, value)
+    }
+
+    switch tree.compareKeys(key, node.key) {
+    case KeyIsLess:
+        node.left = tree.insertNodeRecursive(node.left, key, value)
+        tree.setParent(node.left, node)
+    case KeyIsGreater:
+        node.right = tree.insertNodeRecursive(node.right, key, value)
+        tree.setParent(node.right, node)
+    default:
+        if tree.onInsert == nil {
+            node.value = value
+        } else {
+            node.value = tree.onInsert(key, node.value, value)
+        }
+    }
+    return tree.balance(node)
+}
+
+// deleteNodeRecursive is the recursive delete used as the baseline of the benchmarks,
+// maintaining the parent links as the iterative delete does
+func (tree *RbTree) deleteNodeRecursive(node *rbNode, key RbKey) *rbNode {
+    if node == nil {
+        return nil
+    }
+    
+    cmp := tree.compareKeys(key, node.key)
+    if cmp == KeyIsLess {
+        if isBlack(node.left) && !isRed(node.left.left) {
+            node = tree.moveRedLeft(node)
+        }
+        node.left = tree.deleteNodeRecursive(node.left, key)
+        tree.setParent(node.left, node)
+    } else {
+        if cmp == KeysAreEqual && tree.onDelete != nil {
+            value := tree.onDelete(key, node.value)
+            if value != nil {
+                node.value = value
+                return node
+            }
+        }
+        
+        if isRed(node.left) {
+            node = tree.rotateRight(node)
+        }
+        
+        if isBlack(node.right) && !isRed(node.right.left) {
+            node = tree.moveRedRight(node)
+        }
+        
+        if tree.compareKeys(key, node.key) != KeysAreEqual {
+            node.right = tree.deleteNodeRecursive(node.right, key)
+            tree.setParent(node.right, node)
+        } else {
+            if node.right == nil {
+                tree.count--
+                tree.releaseNode(node)
+                return nil
+            }
+
+            rm := min(node.right)
+            node.key   = rm.key
+            node.value = rm.value
+            node.right = tree.deleteMinRecursive(node.right)
+            tree.setParent(node.right, node)
+
+            rm.left = nil
+            rm.right = nil
+            tree.releaseNode(rm)
+            
+            tree.count--
+        }
+    }
+    return tree.balance(node)
+}
+
+// floorRecursive is the recursive floor used as the baseline of the benchmarks
+func floorRecursive(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    if node == nil {
+        return nil
+    }
+    
+    switch compareKeys(compare, key, node.key) {
+    case KeysAreEqual:
+        return node
+    case KeyIsLess:
+        return floorRecursive(node.left, key, compare)
+    default:
+        fn := floorRecursive(node.right, key, compare)
+        if fn != nil {
+            return fn
+        }
+        return node
+    }
+}
+
+// deleteMinRecursive is the recursive deleteMin used by deleteNodeRecursive
+func (tree *RbTree) deleteMinRecursive(node *rbNode) *rbNode {
+    if node.left == nil {
+        return nil
+    }
+    if isBlack(node.left) && !isRed(node.left.left) {
+        node = tree.moveRedLeft(node)
+    }
+    node.left = tree.deleteMinRecursive(node.left)
+    tree.setParent(node.left, node)
+    return tree.balance(node)
+}
+
+// sameShape checks if the subtrees have the same keys, colors and sizes at the same positions
+func sameShape(a, b *rbNode) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return a.key.ComparedTo(b.key) == KeysAreEqual && a.color == b.color && a.size == b.size &&
+        sameShape(a.left, b.left) && sameShape(a.right, b.right)
+}
+
+func TestIterativePaths(t *testing.T) {
+    iterative, recursive := NewRbTree(), NewRbTree()
+    r := rand.New(rand.NewSource(23))
+    for i := 0; i < 20000; i++ {
+        key := newKey(IntKey(r.Intn(4000)))
+        if r.Intn(3) == 0 {
+            iterative.Delete(key)
+            recursive.root = recursive.deleteNodeRecursive(recursive.root, key)
+        } else {
+            iterative.Insert(key, i)
+            recursive.root = recursive.insertNodeRecursive(recursive.root, key, i)
+        }
+        if recursive.root != nil {
+            recursive.root.color = black
+        }
+    }
+    checkRbTree(t, iterative.root)
+    if iterative.Count() != recursive.count || !sameShape(iterative.root, recursive.root) {
+        t.Fatalf("iterative and recursive paths built different trees")
+    }
+
+    for i := -1; i <= 4001; i++ {
+        key := newKey(IntKey(i))
+        if floor(iterative.root, key, nil) != floorRecursive(iterative.root, key, nil) {
+            t.Fatalf("floor(%d) differs from the recursive floor", i)
+        }
+    }
+
+    var keys []RbKey
+    iterator, _ := iterative.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        keys = append(keys, key)
+        if len(keys) == 100 {
+            iterator.Close()
+        }
+    })
+    if n, _ := iterator.Between(newKey(IntKey(1000)), newKey(IntKey(3000))); n != 100 {
+        t.Fatalf("Between stopped after %d items, want 100", n)
+    }
+    for i := 1; i < len(keys); i++ {
+        if keys[i - 1].ComparedTo(keys[i]) != KeyIsLess || keys[0].ComparedTo(newKey(IntKey(1000))) == KeyIsLess {
+            t.Fatalf("Between yielded %v after %v", keys[i], keys[i - 1])
+        }
+    }
+}
+
+// benchmarkKeys returns the keys 0..n-1 in a random order
+func benchmarkKeys(n int) []IntKey {
+    keys := make([]IntKey, n)
+    for i, j := range rand.New(rand.NewSource(23)).Perm(n) {
+        keys[i] = IntKey(j)
+    }
+    return keys
+}
+
+// benchmarkTree returns a tree holding the keys
+func benchmarkTree(keys []IntKey) *RbTree {
+    tree := NewRbTree()
+    for i := range keys {
+        tree.Insert(&keys[i], nil)
+    }
+    return tree
+}
+
+func BenchmarkInsertIterative(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        tree := NewRbTree()
+        for i := range keys {
+            tree.root = tree.insertNode(tree.root, &keys[i], nil)
+            tree.root.color = black
+        }
+    }
+}
+
+func BenchmarkInsertRecursive(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        tree := NewRbTree()
+        for i := range keys {
+            tree.root = tree.insertNodeRecursive(tree.root, &keys[i], nil)
+            tree.root.color = black
+        }
+    }
+}
+
+func BenchmarkDeleteIterative(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    for n := 0; n < b.N; n++ {
+        b.StopTimer()
+        tree := benchmarkTree(keys)
+        b.StartTimer()
+        for i := range keys {
+            tree.root = tree.deleteNode(tree.root, &keys[i])
+            if tree.root != nil {
+                tree.root.color = black
+            }
+        }
+    }
+}
+
+func BenchmarkDeleteRecursive(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    for n := 0; n < b.N; n++ {
+        b.StopTimer()
+        tree := benchmarkTree(keys)
+        b.StartTimer()
+        for i := range keys {
+            tree.root = tree.deleteNodeRecursive(tree.root, &keys[i])
+            if tree.root != nil {
+                tree.root.color = black
+            }
+        }
+    }
+}
+
+func BenchmarkFloorIterative(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    tree := benchmarkTree(keys)
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        floor(tree.root, &keys[n % len(keys)], nil)
+    }
+}
+
+func BenchmarkFloorRecursive(b *testing.B) {
+    keys := benchmarkKeys(100000)
+    tree := benchmarkTree(keys)
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        floorRecursive(tree.root, &keys[n % len(keys)], nil)
+    }
+}
+
+// benchmarkWalk benchmarks the walk on the items of a tree between the bounds
+func benchmarkWalk(b *testing.B, walk func(context *rbIterationContext, root *rbNode, lo, hi RbKey)) {
+    tree := benchmarkTree(benchmarkKeys(100000))
+    iterator, _ := tree.NewRbIterator(nilIterationCallback)
+    context := iterator.(*rbIterationContext)
+    lo, hi := newKey(IntKey(10000)), newKey(IntKey(90000))
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        context.state = iterWalking
+        context.version = tree.version
+        walk(context, tree.root, lo, hi)
+    }
+}
+
+func BenchmarkWalk(b *testing.B) {
+    benchmarkWalk(b, func(context *rbIterationContext, root *rbNode, lo, hi RbKey) {
+        context.walk(root, inclusiveBound(lo), inclusiveBound(hi), false)
+    })
+}
+
+func BenchmarkWalkBaseline(b *testing.B) {
+    benchmarkWalk(b, func(context *rbIterationContext, root *rbNode, lo, hi RbKey) {
+        context.walkBetweenRecursive(root, lo, hi)
+    })
+}
+
+func BenchmarkWalkAll(b *testing.B) {
+    benchmarkWalk(b, func(context *rbIterationContext, root *rbNode, lo, hi RbKey) {
+        context.walk(root, nil, nil, false)
+    })
+}
+
+func BenchmarkWalkAllBaseline(b *testing.B) {
+    benchmarkWalk(b, func(context *rbIterationContext, root *rbNode, lo, hi RbKey) {
+        context.walkAllRecursive(root)
+    })
+}
+
+
+// rbSeqWalkRecursive structure is the former walk of the sequences used as the baseline of the benchmarks
+type rbSeqWalkRecursive struct {
+    tree *RbTree
+    version uint32
+    lo, hi *rbBound
+    yield func(RbKey, interface{}) bool
+}
+
+// aboveLo checks if the key is inside the lower bound of the walk
+func (walk *rbSeqWalkRecursive) aboveLo(key RbKey) bool {
+    if walk.lo == nil {
+        return true
+    }
+    cmp := walk.tree.compareKeys(key, walk.lo.key)
+    return cmp == KeyIsGreater || (walk.lo.inclusive && cmp == KeysAreEqual)
+}
+
+// belowHi checks if the key is inside the upper bound of the walk
+func (walk *rbSeqWalkRecursive) belowHi(key RbKey) bool {
+    if walk.hi == nil {
+        return true
+    }
+    cmp := walk.tree.compareKeys(key, walk.hi.key)
+    return cmp == KeyIsLess || (walk.hi.inclusive && cmp == KeysAreEqual)
+}
+
+// ascend walks on the subtree rooted at node in ascending order, returns 'false' if the walk stopped
+func (walk *rbSeqWalkRecursive) ascend(node *rbNode) bool {
+    for node != nil {
+        aboveLo, belowHi := walk.aboveLo(node.key), walk.belowHi(node.key)
+        if aboveLo {
+            if !walk.ascend(node.left) {
+                return false
+            }
+            if walk.version != walk.tree.version {
+                panic(ErrEnumeratorModified)
+            }
+            if belowHi && !walk.yield(node.key, node.value) {
+                return false
+            }
+        }
+        if !belowHi {
+            return true
+        }
+        node = node.right
+    }
+    return true
+}
+
+func BenchmarkSeq(b *testing.B) {
+    tree := benchmarkTree(benchmarkKeys(100000))
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        for range tree.All() {
+        }
+    }
+}
+
+func BenchmarkSeqBaseline(b *testing.B) {
+    tree := benchmarkTree(benchmarkKeys(100000))
+    b.ResetTimer()
+    for n := 0; n < b.N; n++ {
+        walk := &rbSeqWalkRecursive{tree: tree, version: tree.version, yield: func(RbKey, interface{}) bool { return true }}
+        walk.ascend(tree.root)
+    }
+}
@@ -9,9 +9,14 @@ import (
 type RbIterator interface {
     // All iterates on all items of the RbTree
     All() (int, error)
+    // AllDesc iterates on all items of the RbTree in descending order
+    AllDesc() (int, error)
     // Between iterates on the items of the RbTree that the key of the item 
     // is less or equal to loKey and greater or equal to hiKey
     Between(loKey RbKey, hiKey RbKey) (int, error)
+    // BetweenDesc iterates on the items of the RbTree that the key of the item 
+    // is less or equal to loKey and greater or equal to hiKey in descending order
+    BetweenDesc(loKey RbKey, hiKey RbKey) (int, error)
     // ClearData clears all the data stored on the iterator
     ClearData()
     // Close closes the current iteration, so the iteration stops iterating
@@ -23,17 +28,32 @@ type RbIterator interface {
     // LessOrEqual iterates on the items of the RbTree that the key of the item 
     // is less or equal to the given key
     LessOrEqual(key RbKey) (int, error)
+    // LessOrEqualDesc iterates on the items of the RbTree that the key of the item 
+    // is less or equal to the given key in descending order
+    LessOrEqualDesc(key RbKey) (int, error)
     // LessThan iterates on the items of the RbTree that the key of the item 
     // is less than the given key
     LessThan(key RbKey) (int, error)
+    // LessThanDesc iterates on the items of the RbTree that the key of the item 
+    // is less than the given key in descending order
+    LessThanDesc(key RbKey) (int, error)
     // GetData returns the data stored on the iterator with the dataKey 
     GetData(dataKey string) (interface{}, bool)
     // GreaterOrEqual iterates on the items of the RbTree that the key of the item 
     // is greater or equal to the given key
     GreaterOrEqual(key RbKey) (int, error)
+    // GreaterOrEqualDesc iterates on the items of the RbTree that the key of the item 
+    // is greater or equal to the given key in descending order
+    GreaterOrEqualDesc(key RbKey) (int, error)
     // GreaterThan iterates on the items of the RbTree that the key of the item 
     // is greater than the given key
     GreaterThan(key RbKey) (int, error)
+    // GreaterThanDesc iterates on the items of the RbTree that the key of the item 
+    // is greater than the given key in descending order
+    GreaterThanDesc(key RbKey) (int, error)
+    // Prefix iterates on the items of the RbTree with the BytesKey keys
+    // starting with the given prefix
+    Prefix(prefix []byte) (int, error)
     // RemoveData deletes the data stored on the iterator with the dataKey 
     RemoveData(dataKey string)
     // SetData stores the data with the dataKey on the iterator 
@@ -192,42 +212,15 @@ func (context *rbIterationContext) All() (count int, err error) {
     defer func(ctx *rbIterationContext) {        
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
     context.version = tree.version
-    context.walkAll(tree.root)    
+    context.walk(tree.root, nil, nil, false)
     return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) walkAll(node *rbNode) {
-    if node == nil || !context.inWalk() {
-        return
-    }
-    
-    if context.tree == nil || context.version != context.tree.version {
-        panic(ErrEnumeratorModified)
-    }
-    
-    if node.left != nil {
-        context.walkAll(node.left)
-        if !context.inWalk() {
-            return
-        }
-    }
-    
-    context.incrementCount()
-    context.callback(context, node.key, node.value)
-    if !context.inWalk() {
-        return
-    }
-    
-    if node.right != nil {
-        context.walkAll(node.right)
-    }    
-}
-
 func (context *rbIterationContext) Between(loKey RbKey, hiKey RbKey) (count int, err error) {
     if loKey == nil {
         return 0, ArgumentNilError("loKey")
@@ -245,64 +238,63 @@ func (context *rbIterationContext) Between(loKey RbKey, hiKey RbKey) (count int,
     defer func(ctx *rbIterationContext) {
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
-    switch loKey.ComparedTo(hiKey) {
+    if err = tree.checkKeyType(loKey); err == nil {
+        err = tree.checkKeyType(hiKey)
+    }
+    if err != nil {
+        return 0, err
+    }
+    
+    switch context.tree.compareKeys(loKey, hiKey) {
     case KeysAreEqual:
         node := tree.find(loKey)
         if node != nil {
+            context.incrementCount()
             context.callback(context, node.key, node.value)
-            return 1, nil
         }
-        return 0, nil
+        return context.CurrentCount(), nil
     case KeyIsGreater:
         loKey, hiKey = hiKey, loKey
     }
     
     context.version = tree.version
-    context.walkBetween(tree.root, loKey, hiKey)
+    context.walk(tree.root, inclusiveBound(loKey), inclusiveBound(hiKey), false)
     return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) walkBetween(node *rbNode, loKey RbKey, hiKey RbKey) {
-    if node == nil || !context.inWalk() {
-        return
+func (context *rbIterationContext) LessOrEqual(key RbKey) (count int, err error) {
+        if key == nil {
+        return 0, ArgumentNilError("key")
     }
+
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
+    }    
+
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
     
-    if context.tree == nil || context.version != context.tree.version {
-        panic(ErrEnumeratorModified)
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
     }
     
-    cmpLo := int8(loKey.ComparedTo(node.key))
-    if cmpLo < zeroOrEqual {
-        if node.left != nil {
-            context.walkBetween(node.left, loKey, hiKey)
-            if !context.inWalk() {
-                return
-            }
-        }
-    } 
-    
-    cmpHi := int8(hiKey.ComparedTo(node.key))
-    if cmpLo <= zeroOrEqual && cmpHi >= zeroOrEqual {
-        context.incrementCount()
-        context.callback(context, node.key, node.value)
-        if !context.inWalk() {
-            return
-        }
-    } 
-    
-    if cmpHi > zeroOrEqual {
-        if node.right != nil {
-            context.walkBetween(node.right, loKey, hiKey)
-        }    
-    }
+    context.version = tree.version
+    context.walk(tree.root, nil, inclusiveBound(key), false)
+    return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) LessOrEqual(key RbKey) (count int, err error) {
-        if key == nil {
+func (context *rbIterationContext) GreaterOrEqual(key RbKey) (count int, err error) {
+    if key == nil {
         return 0, ArgumentNilError("key")
     }
 
@@ -315,46 +307,47 @@ func (context *rbIterationContext) LessOrEqual(key RbKey) (count int, err error)
     defer func(ctx *rbIterationContext) {
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
+    }
+    
     context.version = tree.version
-    context.walkLessOrEqual(tree.root, key)
+    context.walk(tree.root, inclusiveBound(key), nil, false)
     return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) walkLessOrEqual(node *rbNode, key RbKey) {
-    if node == nil || !context.inWalk() {
-        return
-    }
-    
-    if context.tree == nil || context.version != context.tree.version {
-        panic(ErrEnumeratorModified)
+func (context *rbIterationContext) LessThan(key RbKey) (count int, err error) {
+    if key == nil {
+        return 0, ArgumentNilError("key")
     }
+
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
+    }    
+
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
     
-    if node.left != nil {
-        context.walkLessOrEqual(node.left, key)
-        if !context.inWalk() {
-            return
-        }
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
     }
     
-    cmp := node.key.ComparedTo(key)
-    if cmp == KeyIsLess || cmp == KeysAreEqual {
-        context.incrementCount()
-        context.callback(context, node.key, node.value)
-        if !context.inWalk() {
-            return
-        }
-
-        if node.right != nil {
-            context.walkLessOrEqual(node.right, key)
-        }  
-    }
+    context.version = tree.version
+    context.walk(tree.root, nil, &rbBound{key: key}, false)
+    return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) GreaterOrEqual(key RbKey) (count int, err error) {
+func (context *rbIterationContext) GreaterThan(key RbKey) (count int, err error) {
     if key == nil {
         return 0, ArgumentNilError("key")
     }
@@ -364,50 +357,88 @@ func (context *rbIterationContext) GreaterOrEqual(key RbKey) (count int, err err
     if err != nil {
         return 0, err
     }    
-
+    
     defer func(ctx *rbIterationContext) {
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
+    }
+    
     context.version = tree.version
-    context.walkGreaterOrEqual(tree.root, key)
+    context.walk(tree.root, &rbBound{key: key}, nil, false)
     return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) walkGreaterOrEqual(node *rbNode, key RbKey) {
-    if node == nil || !context.inWalk() {
-        return
+func (context *rbIterationContext) AllDesc() (count int, err error) {
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
     }
     
-    if context.tree == nil || context.version != context.tree.version {
-        panic(ErrEnumeratorModified)
-    }
+    defer func(ctx *rbIterationContext) {        
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
     
-    cmp := node.key.ComparedTo(key)
-    if cmp == KeyIsGreater || cmp == KeysAreEqual {
-        if node.left != nil {
-            context.walkGreaterOrEqual(node.left, key)
-            if !context.inWalk() {
-                return
-            }
-        }
-        
-        context.incrementCount()
-        context.callback(context, node.key, node.value)
-        if !context.inWalk() {
-            return
-        }
+    context.version = tree.version
+    context.walk(tree.root, nil, nil, true)
+    return context.CurrentCount(), nil
+}
+
+func (context *rbIterationContext) BetweenDesc(loKey RbKey, hiKey RbKey) (count int, err error) {
+    if loKey == nil {
+        return 0, ArgumentNilError("loKey")
+    }
+    if hiKey == nil {
+        return 0, ArgumentNilError("hiKey")
     }
 
-    if node.right != nil {
-        context.walkGreaterOrEqual(node.right, key)
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
     }    
+
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
+    
+    if err = tree.checkKeyType(loKey); err == nil {
+        err = tree.checkKeyType(hiKey)
+    }
+    if err != nil {
+        return 0, err
+    }
+    
+    switch context.tree.compareKeys(loKey, hiKey) {
+    case KeysAreEqual:
+        node := tree.find(loKey)
+        if node != nil {
+            context.incrementCount()
+            context.callback(context, node.key, node.value)
+        }
+        return context.CurrentCount(), nil
+    case KeyIsGreater:
+        loKey, hiKey = hiKey, loKey
+    }
+    
+    context.version = tree.version
+    context.walk(tree.root, inclusiveBound(loKey), inclusiveBound(hiKey), true)
+    return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) LessThan(key RbKey) (count int, err error) {
+func (context *rbIterationContext) LessOrEqualDesc(key RbKey) (count int, err error) {
     if key == nil {
         return 0, ArgumentNilError("key")
     }
@@ -421,45 +452,74 @@ func (context *rbIterationContext) LessThan(key RbKey) (count int, err error) {
     defer func(ctx *rbIterationContext) {
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
+    }
+    
     context.version = tree.version
-    context.walkLessThan(tree.root, key)
+    context.walk(tree.root, nil, inclusiveBound(key), true)
     return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) walkLessThan(node *rbNode, key RbKey) {
-    if node == nil || !context.inWalk() {
-        return
+func (context *rbIterationContext) GreaterOrEqualDesc(key RbKey) (count int, err error) {
+    if key == nil {
+        return 0, ArgumentNilError("key")
     }
+
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
+    }    
+
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
     
-    if context.tree == nil || context.version != context.tree.version {
-        panic(ErrEnumeratorModified)
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
     }
     
-    if node.left != nil {
-        context.walkLessThan(node.left, key)
-        if !context.inWalk() {
-            return
-        }
+    context.version = tree.version
+    context.walk(tree.root, inclusiveBound(key), nil, true)
+    return context.CurrentCount(), nil
+}
+
+func (context *rbIterationContext) LessThanDesc(key RbKey) (count int, err error) {
+    if key == nil {
+        return 0, ArgumentNilError("key")
     }
-    
-    if node.key.ComparedTo(key) == KeyIsLess {
-        context.incrementCount()
-        context.callback(context, node.key, node.value)
-        if !context.inWalk() {
-            return
-        }
 
-        if node.right != nil {
-            context.walkLessThan(node.right, key)
-        }  
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
+    }    
+
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
+    
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
     }
+    
+    context.version = tree.version
+    context.walk(tree.root, nil, &rbBound{key: key}, true)
+    return context.CurrentCount(), nil
 }
 
-func (context *rbIterationContext) GreaterThan(key RbKey) (count int, err error) {
+func (context *rbIterationContext) GreaterThanDesc(key RbKey) (count int, err error) {
     if key == nil {
         return 0, ArgumentNilError("key")
     }
@@ -473,40 +533,111 @@ func (context *rbIterationContext) GreaterThan(key RbKey) (count int, err error)
     defer func(ctx *rbIterationContext) {
         atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
         if r := recover(); r != nil {
-            err = r.(error)
+            err = recoveredError(r)
         } 
     }(context)
     
+    if err = tree.checkKeyType(key); err != nil {
+        return 0, err
+    }
+    
     context.version = tree.version
-    context.walkGreaterThan(tree.root, key)
+    context.walk(tree.root, &rbBound{key: key}, nil, true)
     return context.CurrentCount(), nil
 }
+func (context *rbIterationContext) Prefix(prefix []byte) (count int, err error) {
+    var tree *RbTree
+    tree, err = context.checkStateAndGetTree()        
+    if err != nil {
+        return 0, err
+    }    
 
-func (context *rbIterationContext) walkGreaterThan(node *rbNode, key RbKey) {
-    if node == nil || !context.inWalk() {
-        return
+    defer func(ctx *rbIterationContext) {
+        atomic.CompareAndSwapInt32(&ctx.state, iterWalking, iteratorReady)
+        if r := recover(); r != nil {
+            err = recoveredError(r)
+        } 
+    }(context)
+    
+    key := BytesKey(prefix)
+    if err = tree.checkKeyType(&key); err != nil {
+        return 0, err
     }
     
+    context.version = tree.version
+    context.walkPrefix(tree.root, prefix)
+    return context.CurrentCount(), nil
+}
+
+// walk calls the callback for the items of the subtree rooted at node between the bounds
+// while the iteration continues, a nil bound leaves that side of the range open
+func (context *rbIterationContext) walk(node *rbNode, lo, hi *rbBound, descending bool) {
+    if node == nil {
+        return
+    }
+    if lo == nil && hi == nil {
+        context.walkAll(node, descending)
+        return
+    }
+    walker := rbWalker{lo: lo, hi: hi, descending: descending, compare: context.tree.compare}
+    context.walkNode(node, &walker)
+}
+
+// walkPrefix calls the callback for the items of the subtree rooted at node with the BytesKey keys
+// starting with the prefix while the iteration continues
+func (context *rbIterationContext) walkPrefix(node *rbNode, prefix []byte) {
+    if node == nil {
+        return
+    }
+    if prefix == nil {
+        prefix = []byte{}
+    }
+    key := BytesKey(prefix)
+    walker := rbWalker{lo: inclusiveBound(&key), compare: context.tree.compare, prefix: prefix}
+    context.walkNode(node, &walker)
+}
+
+// walkAll calls the callback in order for all items of the subtree, returns false if the walk ended
+func (context *rbIterationContext) walkAll(node *rbNode, descending bool) bool {
+    first, last := node.left, node.right
+    if descending {
+        first, last = last, first
+    }
+    if first != nil && !context.walkAll(first, descending) {
+        return false
+    }
+
     if context.tree == nil || context.version != context.tree.version {
         panic(ErrEnumeratorModified)
     }
-    
-    if node.key.ComparedTo(key) == KeyIsGreater {
-        if node.left != nil {
-            context.walkGreaterThan(node.left, key)
-            if !context.inWalk() {
-                return
-            }
+    context.incrementCount()
+    context.callback(context, node.key, node.value)
+    if !context.inWalk() {
+        return false
+    }
+    return last == nil || context.walkAll(last, descending)
+}
+
+// walkNode calls the callback in order for the items of the subtree inside the bounds of the walker,
+// returns false if the walk ended
+func (context *rbIterationContext) walkNode(node *rbNode, walker *rbWalker) bool {
+    first, last, inFirst, inLast := walker.children(node)
+    if inFirst {
+        if first != nil && !context.walkNode(first, walker) {
+            return false
+        }
+        if !inLast || !walker.hasPrefix(node.key) {
+            return false
+        }
+
+        if context.tree == nil || context.version != context.tree.version {
+            panic(ErrEnumeratorModified)
         }
-        
         context.incrementCount()
         context.callback(context, node.key, node.value)
         if !context.inWalk() {
-            return
+            return false
         }
     }
-
-    if node.right != nil {
-        context.walkGreaterThan(node.right, key)
-    }    
-}
\ No newline at end of file
+    return last == nil || context.walkNode(last, walker)
+}
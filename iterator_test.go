@@ -170,4 +170,229 @@ func TestIterateMap(t *testing.T) {
     } else {
         fmt.Printf("Mem map allocated: %3.3f MB\n", float64(mem2.Alloc - mem1.Alloc)/(1024*1024))
     }
-}
\ No newline at end of file
+}
+
+func TestCursor(t *testing.T) {
+    tree := NewRbTree()
+    for i := 2; i <= 20; i += 2 {
+        key := IntKey(i)
+        tree.Insert(&key, i * 10)
+    }
+
+    cursor := tree.NewCursor()
+    if cursor.Valid() || cursor.Next() || cursor.Key() != nil {
+        t.Fatal("unpositioned cursor is valid")
+    }
+
+    var keys []int
+    for ok := cursor.SeekFirst(); ok; ok = cursor.Next() {
+        if cursor.Value() != int(*cursor.Key().(*IntKey)) * 10 {
+            t.Fatalf("Value() = %v at key %v", cursor.Value(), *cursor.Key().(*IntKey))
+        }
+        keys = append(keys, int(*cursor.Key().(*IntKey)))
+    }
+    if len(keys) != 10 || keys[0] != 2 || keys[9] != 20 {
+        t.Fatalf("forward keys %v", keys)
+    }
+    if cursor.Valid() {
+        t.Fatal("cursor valid after moving past the last key")
+    }
+
+    keys = keys[:0]
+    for ok := cursor.SeekLast(); ok; ok = cursor.Prev() {
+        keys = append(keys, int(*cursor.Key().(*IntKey)))
+    }
+    if len(keys) != 10 || keys[0] != 20 || keys[9] != 2 {
+        t.Fatalf("backward keys %v", keys)
+    }
+
+    key := IntKey(7)
+    if !cursor.Seek(&key) || *cursor.Key().(*IntKey) != 8 {
+        t.Fatalf("Seek(7) positioned at %v", cursor.Key())
+    }
+    key = IntKey(21)
+    if cursor.Seek(&key) {
+        t.Fatal("Seek(21) positioned past the last key")
+    }
+
+    key = IntKey(8)
+    cursor.Seek(&key)
+    tree.Delete(&key)
+    if cursor.Valid() {
+        t.Fatal("cursor valid on a deleted key")
+    }
+    if !cursor.Next() || *cursor.Key().(*IntKey) != 10 {
+        t.Fatalf("Next() after delete positioned at %v", cursor.Key())
+    }
+    if !cursor.Prev() || *cursor.Key().(*IntKey) != 6 {
+        t.Fatalf("Prev() after delete positioned at %v", cursor.Key())
+    }
+}
+
+
+func TestIterateDesc(t *testing.T) {
+    tree := NewRbTree()
+    for i := 1; i <= 1000; i++ {
+        key := IntKey(i)
+        tree.Insert(&key, 10 + i)
+    }
+
+    var keys []int
+    iterator, err := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        keys = append(keys, int(*key.(*IntKey)))
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    collect := func(run func() (int, error)) []int {
+        keys = nil
+        count, err := run()
+        if err != nil {
+            t.Fatal(err)
+        }
+        if count != len(keys) || iterator.CurrentCount() != count {
+            t.Fatalf("count %d, current count %d, collected %d keys", count, iterator.CurrentCount(), len(keys))
+        }
+        return keys
+    }
+
+    loKey, hiKey, key := IntKey(100), IntKey(900), IntKey(500)
+    tests := []struct {
+        name string
+        asc, desc func() (int, error)
+    }{
+        {"All", iterator.All, iterator.AllDesc},
+        {"Between", func() (int, error) { return iterator.Between(&hiKey, &loKey) },
+            func() (int, error) { return iterator.BetweenDesc(&hiKey, &loKey) }},
+        {"BetweenSameKey", func() (int, error) { return iterator.Between(&key, &key) },
+            func() (int, error) { return iterator.BetweenDesc(&key, &key) }},
+        {"LessThan", func() (int, error) { return iterator.LessThan(&key) },
+            func() (int, error) { return iterator.LessThanDesc(&key) }},
+        {"LessOrEqual", func() (int, error) { return iterator.LessOrEqual(&key) },
+            func() (int, error) { return iterator.LessOrEqualDesc(&key) }},
+        {"GreaterThan", func() (int, error) { return iterator.GreaterThan(&key) },
+            func() (int, error) { return iterator.GreaterThanDesc(&key) }},
+        {"GreaterOrEqual", func() (int, error) { return iterator.GreaterOrEqual(&key) },
+            func() (int, error) { return iterator.GreaterOrEqualDesc(&key) }},
+    }
+
+    for _, test := range tests {
+        asc := collect(test.asc)
+        desc := collect(test.desc)
+        if len(asc) == 0 || len(asc) != len(desc) {
+            t.Fatalf("%s: %d ascending keys, %d descending keys", test.name, len(asc), len(desc))
+        }
+        for i := range asc {
+            if asc[i] != desc[len(desc)-1-i] {
+                t.Fatalf("%s: descending order differs at %d: %d != %d", test.name, i, desc[len(desc)-1-i], asc[i])
+            }
+        }
+    }
+}
+
+
+func TestRangeOverFunc(t *testing.T) {
+    tree := NewRbTree()
+    for i := 1; i <= 100; i++ {
+        key := IntKey(i)
+        tree.Insert(&key, 10 + i)
+    }
+
+    collect := func(seq func(yield func(RbKey, interface{}) bool)) []int {
+        var keys []int
+        for key, value := range seq {
+            if value != 10 + int(*key.(*IntKey)) {
+                t.Fatalf("value %v for key %v", value, *key.(*IntKey))
+            }
+            keys = append(keys, int(*key.(*IntKey)))
+        }
+        return keys
+    }
+
+    loKey, hiKey := IntKey(20), IntKey(30)
+    tests := []struct {
+        name string
+        keys []int
+        first, last, count int
+    }{
+        {"All", collect(tree.All()), 1, 100, 100},
+        {"Backward", collect(tree.Backward()), 100, 1, 100},
+        {"Range", collect(tree.Range(&hiKey, &loKey)), 20, 30, 11},
+        {"Range open", collect(tree.Range(nil, &loKey)), 1, 20, 20},
+        {"From", collect(tree.From(&hiKey)), 30, 100, 71},
+        {"Until", collect(tree.Until(&loKey)), 1, 19, 19},
+    }
+    for _, test := range tests {
+        if len(test.keys) != test.count || test.keys[0] != test.first || test.keys[len(test.keys)-1] != test.last {
+            t.Fatalf("%s: got keys %v", test.name, test.keys)
+        }
+    }
+
+    count := 0
+    for key := range tree.All() {
+        if *key.(*IntKey) == 10 {
+            break
+        }
+        count++
+    }
+    if count != 9 {
+        t.Fatalf("break after %d items, want 9", count)
+    }
+
+    defer func() {
+        if r := recover(); r != ErrEnumeratorModified {
+            t.Fatalf("recovered %v, want %v", r, ErrEnumeratorModified)
+        }
+    }()
+    for key := range tree.All() {
+        newKey := *key.(*IntKey) + 1000
+        tree.Insert(&newKey, nil)
+    }
+    t.Fatal("modifying the tree while ranging did not panic")
+}
+
+func TestPrefix(t *testing.T) {
+    tree := NewRbTree()
+    for _, s := range []string{"", "a", "ab", "abc", "abd", "ac", "b", "ba", "\xff", "\xff\xff"} {
+        key := BytesKey(s)
+        tree.Insert(&key, s)
+    }
+
+    var found []string
+    var iterator RbIterator
+
+    expected := map[string][]string{
+        "ab": {"ab", "abc", "abd"},
+        "a": {"a", "ab", "abc", "abd", "ac"},
+        "abc": {"abc"},
+        "abcd": nil,
+        "c": nil,
+        "\xff": {"\xff", "\xff\xff"},
+    }
+    for prefix, keys := range expected {
+        found = found[:0]
+        iterator, _ = tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+            found = append(found, string(*key.(*BytesKey)))
+        })
+        if n, err := iterator.Prefix([]byte(prefix)); err != nil || n != len(keys) {
+            t.Fatalf("Prefix(%q) = %d, %v, want %d", prefix, n, err, len(keys))
+        }
+        for i := range keys {
+            if found[i] != keys[i] {
+                t.Fatalf("Prefix(%q) walked %q, want %q", prefix, found, keys)
+            }
+        }
+    }
+
+    found = found[:0]
+    iterator, _ = tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        found = append(found, string(*key.(*BytesKey)))
+        if len(found) == 2 {
+            iterator.Close()
+        }
+    })
+    if n, _ := iterator.Prefix(nil); n != 2 {
+        t.Fatalf("Prefix(nil) walked %d keys after Close, want 2", n)
+    }
+}
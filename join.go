@@ -0,0 +1,265 @@
+package rbt
+
+// Split moves the items of the tree into two new trees in O(log n), the first one holding
+// the keys less than the given key and the second one holding the keys greater or equal to it.
+// The tree becomes empty after the split.
+func (tree *RbTree) Split(key RbKey) (left, right *RbTree) {
+    tree.version++
+
+    leftRoot, _, found, rightRoot, rightHeight := tree.split(tree.root, blackHeight(tree.root), key)
+    if found != nil {
+        rightRoot, _ = tree.join(nil, 0, tree.own(found), rightRoot, rightHeight)
+    }
+
+    left = tree.newPart(tree.gen)
+    left.setPart(leftRoot)
+    right = tree.newPart(tree.gen)
+    right.setPart(rightRoot)
+
+    tree.root = nil
+    tree.count = 0
+    tree.shared = 0
+    return left, right
+}
+
+// newPart creates an empty tree with the given generation sharing the events of the tree
+func (tree *RbTree) newPart(gen uint32) *RbTree {
+    return &RbTree{
+        version: tree.version,
+        gen: gen,
+        onInsert: tree.onInsert,
+        onDelete: tree.onDelete,
+        valueCodec: tree.valueCodec,
+        compare: tree.compare,
+        keyType: tree.keyType,
+        unlinked: true,
+    }
+}
+
+// setPart makes the subtree the items of the part, the nodes of the part may be shared with other trees
+func (tree *RbTree) setPart(root *rbNode) {
+    tree.root, _ = tree.blacken(root, 0)
+    tree.count = size(tree.root)
+    tree.shared = tree.count
+}
+
+// Join moves the items of the trees into a new tree in O(log n), assuming that
+// all the keys of the first tree are less than the keys of the second tree,
+// otherwise returns ErrKeyRangesOverlap. Both trees become empty after the join.
+func Join(left, right *RbTree) (*RbTree, error) {
+    if left == nil {
+        return nil, ArgumentNilError("left")
+    }
+    if right == nil {
+        return nil, ArgumentNilError("right")
+    }
+    if left.root != nil && right.root != nil &&
+        left.compareKeys(max(left.root).key, min(right.root).key) != KeyIsLess {
+        return nil, ErrKeyRangesOverlap
+    }
+
+    left.version++
+    right.version++
+    if right.version > left.version {
+        left.version = right.version
+    }
+
+    gen := left.gen
+    if left.gen != right.gen {
+        gen = nextGeneration()
+    }
+    result := left.newPart(gen)
+    root, _ := result.join2(left.root, blackHeight(left.root), right.root, blackHeight(right.root))
+    result.setPart(root)
+
+    left.root, left.count, left.shared = nil, 0, 0
+    right.root, right.count, right.shared = nil, 0, 0
+    return result, nil
+}
+
+// blackHeight returns the count of the black nodes on a path from the node to a leaf
+func blackHeight(node *rbNode) int {
+    height := 0
+    for ; node != nil; node = node.left {
+        if node.color == black {
+            height++
+        }
+    }
+    return height
+}
+
+// childHeight returns the black height of the children of a node with the given black height
+func childHeight(node *rbNode, height int) int {
+    if isBlack(node) {
+        return height - 1
+    }
+    return height
+}
+
+// blacken makes the root of the subtree black and returns the new black height of the subtree
+func (tree *RbTree) blacken(node *rbNode, height int) (*rbNode, int) {
+    if isRed(node) {
+        node = tree.own(node)
+        node.color = black
+        height++
+    }
+    return node, height
+}
+
+// join concatenates the left subtree, the owned middle node and the right subtree,
+// assuming that all the keys of the left subtree are less than the key of the middle node
+// and all the keys of the right subtree are greater, and returns the joined tree with its black height
+func (tree *RbTree) join(left *rbNode, leftHeight int, middle *rbNode, right *rbNode, rightHeight int) (*rbNode, int) {
+    left, leftHeight = tree.blacken(left, leftHeight)
+    right, rightHeight = tree.blacken(right, rightHeight)
+
+    var root *rbNode
+    var height int
+
+    switch {
+    case leftHeight > rightHeight:
+        root, height = tree.joinRight(left, leftHeight, middle, right, rightHeight), leftHeight
+    case leftHeight < rightHeight:
+        root, height = tree.joinLeft(right, rightHeight, middle, left, leftHeight), rightHeight
+    default:
+        middle.left = left
+        middle.right = right
+        middle.color = black
+        updateSize(middle)
+        return middle, leftHeight + 1
+    }
+    return tree.blacken(root, height)
+}
+
+// joinRight walks down the right spine of the higher left subtree to the black node having
+// the same black height with the right subtree and links them under the red middle node
+func (tree *RbTree) joinRight(node *rbNode, height int, middle *rbNode, right *rbNode, rightHeight int) *rbNode {
+    path := tree.newPath()
+    for height != rightHeight || isRed(node) {
+        node = tree.own(node)
+        path = append(path, rbStep{node: node})
+        height = childHeight(node, height)
+        node = node.right
+    }
+
+    middle.left = node
+    middle.right = right
+    middle.color = red
+    updateSize(middle)
+
+    root := tree.rebalance(path, middle)
+    tree.releasePath(path)
+    return root
+}
+
+// joinLeft walks down the left spine of the higher right subtree to the black node having
+// the same black height with the left subtree and links them under the red middle node
+func (tree *RbTree) joinLeft(node *rbNode, height int, middle *rbNode, left *rbNode, leftHeight int) *rbNode {
+    path := tree.newPath()
+    for height != leftHeight || isRed(node) {
+        node = tree.own(node)
+        path = append(path, rbStep{node: node, left: true})
+        height = childHeight(node, height)
+        node = node.left
+    }
+
+    middle.left = left
+    middle.right = node
+    middle.color = red
+    updateSize(middle)
+
+    root := tree.rebalance(path, middle)
+    tree.releasePath(path)
+    return root
+}
+
+// join2 concatenates the left and right subtrees, assuming that all the keys
+// of the left subtree are less than the keys of the right subtree
+func (tree *RbTree) join2(left *rbNode, leftHeight int, right *rbNode, rightHeight int) (*rbNode, int) {
+    if left == nil {
+        return right, rightHeight
+    }
+    if right == nil {
+        return left, leftHeight
+    }
+
+    left, leftHeight, last := tree.splitLast(left, leftHeight)
+    return tree.join(left, leftHeight, tree.own(last), right, rightHeight)
+}
+
+// rbSplitStep structure is a node on the path of a split with the black height of its children
+// and its child not taken by the path
+type rbSplitStep struct {
+    node *rbNode
+    other *rbNode
+    height int
+    left bool
+}
+
+// newSplitPath returns the empty split path of the tree having room for a subtree with the given black height,
+// the path is kept in the tree between the splits
+func (tree *RbTree) newSplitPath(height int) []rbSplitStep {
+    if cap(tree.splitPath) < 2 * height + 1 {
+        tree.splitPath = make([]rbSplitStep, 0, 2 * height + 1)
+    }
+    return tree.splitPath[:0]
+}
+
+// releaseSplitPath keeps the split path for the next split dropping its nodes
+func (tree *RbTree) releaseSplitPath(path []rbSplitStep) {
+    clear(path)
+    tree.splitPath = path[:0]
+}
+
+// splitLast removes the node with the largest key from the subtree and returns
+// the remaining subtree with its black height and the removed node
+func (tree *RbTree) splitLast(node *rbNode, height int) (*rbNode, int, *rbNode) {
+    path := tree.newSplitPath(height)
+    for node.right != nil {
+        nodeHeight := childHeight(node, height)
+        path = append(path, rbSplitStep{node: node, other: node.left, height: nodeHeight})
+        node, height = node.right, nodeHeight
+    }
+
+    last := node
+    root, rootHeight := node.left, childHeight(node, height)
+    for i := len(path) - 1; i >= 0; i-- {
+        step := path[i]
+        root, rootHeight = tree.join(step.other, step.height, tree.own(step.node), root, rootHeight)
+    }
+    tree.releaseSplitPath(path)
+    return root, rootHeight, last
+}
+
+// split cuts the subtree into the subtrees with the keys less than and greater than the given key,
+// returns them with their black heights and the node with the given key if exists
+func (tree *RbTree) split(node *rbNode, height int, key RbKey) (left *rbNode, leftHeight int, found *rbNode, right *rbNode, rightHeight int) {
+    path := tree.newSplitPath(height)
+    for node != nil {
+        nodeHeight := childHeight(node, height)
+        switch compareKeys(tree.compare, key, node.key) {
+        case KeyIsLess:
+            path = append(path, rbSplitStep{node: node, other: node.right, height: nodeHeight, left: true})
+            node = node.left
+        case KeyIsGreater:
+            path = append(path, rbSplitStep{node: node, other: node.left, height: nodeHeight})
+            node = node.right
+        default:
+            left, leftHeight, found, right, rightHeight = node.left, nodeHeight, node, node.right, nodeHeight
+            node = nil
+            continue
+        }
+        height = nodeHeight
+    }
+
+    for i := len(path) - 1; i >= 0; i-- {
+        step := path[i]
+        if step.left {
+            right, rightHeight = tree.join(right, rightHeight, tree.own(step.node), step.other, step.height)
+        } else {
+            left, leftHeight = tree.join(step.other, step.height, tree.own(step.node), left, leftHeight)
+        }
+    }
+    tree.releaseSplitPath(path)
+    return left, leftHeight, found, right, rightHeight
+}
@@ -0,0 +1,235 @@
+package rbt
+
+import (
+    "bytes"
+    "encoding/json"
+    "reflect"
+    "sort"
+    "sync"
+)
+
+// jsonPair structure is the JSON form of an item of a tree with non-string keys
+type jsonPair struct {
+    Type string `json:"type"`
+    Key json.RawMessage `json:"key"`
+    Value interface{} `json:"value"`
+}
+
+// jsonKeyTypes holds the registered JSON key types by their names and types
+var jsonKeyTypes = struct {
+    sync.RWMutex
+    byName map[string]reflect.Type
+    byType map[reflect.Type]string
+}{
+    byName: make(map[string]reflect.Type),
+    byType: make(map[reflect.Type]string),
+}
+
+func init() {
+    RegisterJSONKeyType("nil", &NilKey{})
+    RegisterJSONKeyType("bool", new(BoolKey))
+    RegisterJSONKeyType("byte", new(ByteKey))
+    RegisterJSONKeyType("int", new(IntKey))
+    RegisterJSONKeyType("int8", new(Int8Key))
+    RegisterJSONKeyType("int16", new(Int16Key))
+    RegisterJSONKeyType("int32", new(Int32Key))
+    RegisterJSONKeyType("int64", new(Int64Key))
+    RegisterJSONKeyType("uint", new(UintKey))
+    RegisterJSONKeyType("uint8", new(Uint8Key))
+    RegisterJSONKeyType("uint16", new(Uint16Key))
+    RegisterJSONKeyType("uint32", new(Uint32Key))
+    RegisterJSONKeyType("uint64", new(Uint64Key))
+    RegisterJSONKeyType("float32", new(Float32Key))
+    RegisterJSONKeyType("float64", new(Float64Key))
+    RegisterJSONKeyType("string", new(StringKey))
+    RegisterJSONKeyType("bytes", new(BytesKey))
+}
+
+// RegisterJSONKeyType registers the pointer type of the sample key with the given name
+// used in the JSON form of the trees. The keys of the type are marshaled and unmarshaled with encoding/json.
+// Returns ErrKeyCodecExists if the name or the key type is already registered.
+func RegisterJSONKeyType(name string, sample RbKey) error {
+    if len(name) == 0 {
+        return ArgumentNilError("name")
+    }
+    if sample == nil {
+        return ArgumentNilError("sample")
+    }
+
+    keyType := reflect.TypeOf(sample)
+    if keyType.Kind() != reflect.Ptr {
+        return ErrKeyCodecNotFound
+    }
+
+    jsonKeyTypes.Lock()
+    defer jsonKeyTypes.Unlock()
+
+    if _, ok := jsonKeyTypes.byName[name]; ok {
+        return ErrKeyCodecExists
+    }
+    if _, ok := jsonKeyTypes.byType[keyType]; ok {
+        return ErrKeyCodecExists
+    }
+    jsonKeyTypes.byName[name] = keyType
+    jsonKeyTypes.byType[keyType] = name
+    return nil
+}
+
+// MarshalJSON encodes the items of the tree in ascending order. The trees having only StringKey keys
+// are encoded as an object, others as an array of {"type": ..., "key": ..., "value": ...} objects,
+// the type is the name of the key type registered with RegisterJSONKeyType.
+func (tree *RbTree) MarshalJSON() ([]byte, error) {
+    stringKeys := tree.root != nil
+    for key := range tree.All() {
+        if _, ok := key.(*StringKey); !ok {
+            stringKeys = false
+            break
+        }
+    }
+
+    var buf bytes.Buffer
+    if stringKeys {
+        buf.WriteByte('{')
+    } else {
+        buf.WriteByte('[')
+    }
+
+    jsonKeyTypes.RLock()
+    defer jsonKeyTypes.RUnlock()
+
+    first := true
+    for key, value := range tree.All() {
+        if !first {
+            buf.WriteByte(',')
+        }
+        first = false
+
+        var data []byte
+        var err error
+        if stringKeys {
+            if data, err = json.Marshal(string(*key.(*StringKey))); err == nil {
+                buf.Write(data)
+                buf.WriteByte(':')
+                data, err = json.Marshal(value)
+            }
+        } else {
+            name, ok := jsonKeyTypes.byType[reflect.TypeOf(key)]
+            if !ok {
+                return nil, ErrKeyCodecNotFound
+            }
+            pair := jsonPair{Type: name, Value: value}
+            if pair.Key, err = json.Marshal(key); err == nil {
+                data, err = json.Marshal(&pair)
+            }
+        }
+        if err != nil {
+            return nil, err
+        }
+        buf.Write(data)
+    }
+
+    if stringKeys {
+        buf.WriteByte('}')
+    } else {
+        buf.WriteByte(']')
+    }
+    return buf.Bytes(), nil
+}
+
+// UnmarshalJSON replaces the items of the tree with the items decoded from the JSON form created by MarshalJSON,
+// the objects are decoded into StringKey keys. The values are decoded as with encoding/json into an interface{}.
+// The keys must be of one key type, the numeric key types may be mixed as in TryInsert.
+// The items are sorted if needed and the tree is built in linear time.
+// The tree is not changed if an error is returned.
+func (tree *RbTree) UnmarshalJSON(data []byte) error {
+    decoder := json.NewDecoder(bytes.NewReader(data))
+    token, err := decoder.Token()
+    if err != nil {
+        return err
+    }
+
+    var keys []RbKey
+    var values []interface{}
+
+    switch token {
+    case nil:
+        return nil
+    case json.Delim('{'):
+        for decoder.More() {
+            if token, err = decoder.Token(); err != nil {
+                return err
+            }
+            var value interface{}
+            if err = decoder.Decode(&value); err != nil {
+                return err
+            }
+            key := StringKey(token.(string))
+            keys = append(keys, &key)
+            values = append(values, value)
+        }
+    case json.Delim('['):
+        jsonKeyTypes.RLock()
+        defer jsonKeyTypes.RUnlock()
+
+        for decoder.More() {
+            var pair jsonPair
+            if err = decoder.Decode(&pair); err != nil {
+                return err
+            }
+            keyType, ok := jsonKeyTypes.byName[pair.Type]
+            if !ok {
+                return ErrKeyCodecNotFound
+            }
+            key := reflect.New(keyType.Elem())
+            if err = json.Unmarshal(pair.Key, key.Interface()); err != nil {
+                return err
+            }
+            keys = append(keys, key.Interface().(RbKey))
+            values = append(values, pair.Value)
+        }
+    default:
+        return ErrInvalidFormat
+    }
+
+    if _, err = decoder.Token(); err != nil {
+        return err
+    }
+    for _, key := range keys {
+        if !keyTypesMatch(reflect.TypeOf(key), reflect.TypeOf(keys[0])) {
+            return ErrInvalidFormat
+        }
+    }
+
+    items := &sortedItems{keys: keys, values: values, compare: tree.compare}
+    if !sort.IsSorted(items) {
+        sort.Stable(items)
+    }
+
+    return tree.BuildFromSorted(func(yield func(RbKey, interface{}) bool) {
+        for i, key := range keys {
+            if !yield(key, values[i]) {
+                return
+            }
+        }
+    })
+}
+
+// sortedItems structure used for sorting the decoded keys with their values
+type sortedItems struct {
+    keys []RbKey
+    values []interface{}
+    compare KeyComparator
+}
+
+func (items *sortedItems) Len() int {
+    return len(items.keys)
+}
+
+func (items *sortedItems) Less(i, j int) bool {
+    return compareKeys(items.compare, items.keys[i], items.keys[j]) == KeyIsLess
+}
+
+func (items *sortedItems) Swap(i, j int) {
+    items.keys[i], items.keys[j] = items.keys[j], items.keys[i]
+    items.values[i], items.values[j] = items.values[j], items.values[i]
+}
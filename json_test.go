@@ -0,0 +1,91 @@
+package rbt
+
+import (
+    "encoding/json"
+    "testing"
+)
+
+func TestMarshalJSON(t *testing.T) {
+    tree := NewRbTree()
+    for _, s := range []string{"b", "c", "a"} {
+        tree.Insert(newKey(StringKey(s)), len(s))
+    }
+
+    data, err := json.Marshal(tree)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if string(data) != `{"a":1,"b":1,"c":1}` {
+        t.Fatalf("Marshal of string keys = %s", data)
+    }
+
+    tree = NewRbTree()
+    for _, i := range []int{3, -1, 2} {
+        tree.Insert(newKey(IntKey(i)), map[string]interface{}{"n": i})
+    }
+    if data, err = json.Marshal(tree); err != nil {
+        t.Fatal(err)
+    }
+    expected := `[{"type":"int","key":-1,"value":{"n":-1}},{"type":"int","key":2,"value":{"n":2}},{"type":"int","key":3,"value":{"n":3}}]`
+    if string(data) != expected {
+        t.Fatalf("Marshal of int keys = %s, want %s", data, expected)
+    }
+
+    if data, err = json.Marshal(NewRbTree()); err != nil || string(data) != "[]" {
+        t.Fatalf("Marshal of empty tree = %s, %v", data, err)
+    }
+
+    tree = NewRbTree()
+    tree.Insert(&unregisteredKey{}, 1)
+    if _, err = tree.MarshalJSON(); err != ErrKeyCodecNotFound {
+        t.Fatalf("Marshal of unregistered key returned %v, want %v", err, ErrKeyCodecNotFound)
+    }
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+    keys := [][]RbKey{
+        {newKey(StringKey("x")), newKey(StringKey("y"))},
+        {newKey(IntKey(-7)), newKey(IntKey(9))},
+        {newKey(Uint64Key(1 << 63)), newKey(Uint64Key(3))},
+        {newKey(Float64Key(-0.5)), newKey(Float64Key(2.25))},
+        {newKey(BoolKey(true)), newKey(BoolKey(false))},
+        {&NilKey{}},
+        {newKey(IntKey(-3)), newKey(Uint64Key(1 << 63)), newKey(Float64Key(0.5)), &NilKey{}},
+    }
+
+    for _, list := range keys {
+        tree := NewRbTree()
+        for i, key := range list {
+            tree.Insert(key, float64(i))
+        }
+
+        data, err := json.Marshal(tree)
+        if err != nil {
+            t.Fatal(err)
+        }
+        result := NewRbTree()
+        if err = json.Unmarshal(data, result); err != nil {
+            t.Fatalf("Unmarshal of %s returned %v", data, err)
+        }
+        checkRbTree(t, result.root)
+        checkSameItems(t, result, tree)
+    }
+
+    tree := NewRbTree()
+    if err := json.Unmarshal([]byte(`{"b":true,"a":[1,"x"]}`), tree); err != nil {
+        t.Fatal(err)
+    }
+    if key, _ := tree.Min(); tree.Count() != 2 || *key.(*StringKey) != "a" {
+        t.Fatalf("Unmarshal of unsorted object failed")
+    }
+
+    for _, data := range []string{`5`, `[{"type":"unknown","key":1}]`, `[{"type":"int","key":"x"}]`,
+        `[{"type":"int","key":1},{"type":"string","key":"x"}]`, `[{"type":"int","key":1},{"type":"int","key":1}]`, `{"a":1`} {
+        if err := json.Unmarshal([]byte(data), tree); err == nil {
+            t.Fatalf("Unmarshal of %s succeeded", data)
+        }
+    }
+    if tree.Count() != 2 {
+        t.Fatalf("tree changed by failed Unmarshal")
+    }
+}
@@ -0,0 +1,165 @@
+package rbt
+
+import (
+    "iter"
+    "reflect"
+)
+
+// NewRbTreeWithKeyType creates a new RbTree accepting only the keys of the same type as sample
+// in its Try operations and iterators and returns its address, returns nil if sample is nil
+func NewRbTreeWithKeyType(sample RbKey) *RbTree {
+    if sample == nil {
+        return nil
+    }
+    return &RbTree{
+        keyType: reflect.TypeOf(sample),
+    }
+}
+
+// KeyType returns the declared key type of the tree if exists, otherwise the type of the keys stored,
+// returns nil if the tree is empty and has no declared key type
+func (tree *RbTree) KeyType() reflect.Type {
+    if tree.keyType != nil {
+        return tree.keyType
+    }
+    if tree.root != nil {
+        return reflect.TypeOf(tree.root.key)
+    }
+    return nil
+}
+
+// checkKeyType returns ErrKeyTypeMismatch if the type of the key differs from the key type of the tree,
+// the numeric key types and NilKey are accepted in the trees of each other
+func (tree *RbTree) checkKeyType(key RbKey) error {
+    keyType := tree.KeyType()
+    if keyType == nil {
+        return nil
+    }
+    if !keyTypesMatch(reflect.TypeOf(key), keyType) {
+        return ErrKeyTypeMismatch
+    }
+    return nil
+}
+
+// keyTypesMatch returns 'true' if the keys of the types can be stored in the same tree,
+// the numeric key types and NilKey match each other
+func keyTypesMatch(a, b reflect.Type) bool {
+    return a == b || (numericKeyTypes[a] && numericKeyTypes[b])
+}
+
+// checkBoundKeyTypes returns ErrKeyTypeMismatch if the type of any non-nil bound key differs from the key type of the tree
+func (tree *RbTree) checkBoundKeyTypes(loKey RbKey, hiKey RbKey) error {
+    if loKey != nil {
+        if err := tree.checkKeyType(loKey); err != nil {
+            return err
+        }
+    }
+    if hiKey != nil {
+        return tree.checkKeyType(hiKey)
+    }
+    return nil
+}
+
+// TryInsert inserts the given key and value into the tree,
+// returns ErrKeyTypeMismatch instead of inserting if the key type differs from the key type of the tree
+func (tree *RbTree) TryInsert(key RbKey, value interface{}) error {
+    if key == nil {
+        return ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return err
+    }
+    tree.Insert(key, value)
+    return nil
+}
+
+// TryGet returns the stored value if key found and 'true',
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryGet(key RbKey) (interface{}, bool, error) {
+    if key == nil {
+        return nil, false, ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return nil, false, err
+    }
+    value, ok := tree.Get(key)
+    return value, ok, nil
+}
+
+// TryDelete deletes the given key from the tree,
+// returns ErrKeyTypeMismatch instead of deleting if the key type differs from the key type of the tree
+func (tree *RbTree) TryDelete(key RbKey) error {
+    if key == nil {
+        return ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return err
+    }
+    tree.Delete(key)
+    return nil
+}
+
+// TryFloor returns the largest key in the tree less than or equal to key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryFloor(key RbKey) (RbKey, interface{}, error) {
+    if key == nil {
+        return nil, nil, ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return nil, nil, err
+    }
+    floorKey, value := tree.Floor(key)
+    return floorKey, value, nil
+}
+
+// TryCeiling returns the smallest key in the tree greater than or equal to key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryCeiling(key RbKey) (RbKey, interface{}, error) {
+    if key == nil {
+        return nil, nil, ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return nil, nil, err
+    }
+    ceilingKey, value := tree.Ceiling(key)
+    return ceilingKey, value, nil
+}
+
+// TryRank returns the count of the keys in the tree strictly less than the given key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryRank(key RbKey) (int, error) {
+    if key == nil {
+        return 0, ArgumentNilError("key")
+    }
+    if err := tree.checkKeyType(key); err != nil {
+        return 0, err
+    }
+    return tree.Rank(key), nil
+}
+
+// TryRange returns the sequence of Range,
+// returns ErrKeyTypeMismatch if the type of a bound key differs from the key type of the tree
+func (tree *RbTree) TryRange(loKey RbKey, hiKey RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(loKey, hiKey); err != nil {
+        return nil, err
+    }
+    return tree.Range(loKey, hiKey), nil
+}
+
+// TryFrom returns the sequence of From,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryFrom(key RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(key, nil); err != nil {
+        return nil, err
+    }
+    return tree.From(key), nil
+}
+
+// TryUntil returns the sequence of Until,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *RbTree) TryUntil(key RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(nil, key); err != nil {
+        return nil, err
+    }
+    return tree.Until(key), nil
+}
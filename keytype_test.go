@@ -0,0 +1,184 @@
+package rbt
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func TestKeyTypeMismatch(t *testing.T) {
+    tree := NewRbTree()
+    if tree.KeyType() != nil {
+        t.Fatalf("KeyType() of an empty tree = %v, want nil", tree.KeyType())
+    }
+    if _, ok, err := tree.TryGet(newKey(StringKey("1"))); ok || err != nil {
+        t.Fatalf("TryGet on an empty tree = %v, %v", ok, err)
+    }
+
+    for i := 0; i < 10; i++ {
+        if err := tree.TryInsert(newKey(IntKey(i)), i); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if tree.KeyType() != reflect.TypeOf(newKey(IntKey(0))) {
+        t.Fatalf("KeyType() = %v, want *IntKey", tree.KeyType())
+    }
+
+    if err := tree.TryInsert(newKey(StringKey("20")), 20); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsert of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, _, err := tree.TryGet(newKey(StringKey("5"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryGet of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if err := tree.TryDelete(newKey(StringKey("5"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryDelete of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if err := tree.TryInsert(nil, 0); err == nil {
+        t.Fatalf("TryInsert of nil key succeeded")
+    }
+    if tree.Count() != 10 {
+        t.Fatalf("Count() = %d, want 10", tree.Count())
+    }
+
+    if value, ok, err := tree.TryGet(newKey(IntKey(5))); !ok || err != nil || value != 5 {
+        t.Fatalf("TryGet(5) = %v, %v, %v", value, ok, err)
+    }
+    if err := tree.TryDelete(newKey(IntKey(5))); err != nil || tree.Exists(newKey(IntKey(5))) {
+        t.Fatalf("TryDelete(5) = %v", err)
+    }
+
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {})
+    if _, err := iterator.LessThan(newKey(StringKey("3"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("LessThan of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := iterator.Between(newKey(IntKey(1)), newKey(StringKey("3"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("Between of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := iterator.Prefix([]byte("a")); err != ErrKeyTypeMismatch {
+        t.Fatalf("Prefix on *IntKey tree = %v, want ErrKeyTypeMismatch", err)
+    }
+    if n, err := iterator.LessThan(newKey(IntKey(3))); err != nil || n != 3 {
+        t.Fatalf("LessThan(3) = %d, %v, want 3", n, err)
+    }
+}
+
+func TestDeclaredKeyType(t *testing.T) {
+    if NewRbTreeWithKeyType(nil) != nil {
+        t.Fatalf("NewRbTreeWithKeyType(nil) is not nil")
+    }
+
+    tree := NewRbTreeWithKeyType(newKey(StringKey("")))
+    if err := tree.TryInsert(newKey(IntKey(1)), 1); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsert of *IntKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if err := tree.TryInsert(newKey(StringKey("a")), 1); err != nil {
+        t.Fatal(err)
+    }
+
+    tree.Delete(newKey(StringKey("a")))
+    if err := tree.TryInsert(newKey(IntKey(1)), 1); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsert of *IntKey into the emptied tree = %v, want ErrKeyTypeMismatch", err)
+    }
+
+    clone := tree.Snapshot().ToRbTree()
+    if err := clone.TryInsert(newKey(IntKey(1)), 1); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsert of *IntKey into the snapshot = %v, want ErrKeyTypeMismatch", err)
+    }
+}
+
+func TestIterationPanic(t *testing.T) {
+    tree := NewRbTree()
+    for i := 0; i < 10; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        panic("callback failed")
+    })
+    _, err := iterator.All()
+    if err == nil {
+        t.Fatalf("All() with a panicking callback returned no error")
+    }
+    if errNo, ok := err.(interface{ ErrorNo() ErrNo }); !ok || errNo.ErrorNo() != ErrNoIterationPanicked {
+        t.Fatalf("All() with a panicking callback = %v, want ErrNoIterationPanicked", err)
+    }
+    if _, err = iterator.All(); err == nil {
+        t.Fatalf("iterator not ready after a panicking callback")
+    }
+}
+
+func TestKeyTypeMismatchErrors(t *testing.T) {
+    tree := NewRbTree()
+    for i := 0; i < 10; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+    key := newKey(StringKey("5"))
+
+    if _, _, err := tree.TryFloor(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryFloor of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, _, err := tree.TryCeiling(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryCeiling of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := tree.TryRank(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryRank of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := tree.TryRange(newKey(IntKey(1)), key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryRange of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := tree.TryFrom(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryFrom of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, err := tree.TryUntil(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryUntil of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+
+    if k, v, err := tree.TryFloor(newKey(IntKey(20))); err != nil || *k.(*IntKey) != 9 || v != 9 {
+        t.Fatalf("TryFloor(20) = %v, %v, %v", k, v, err)
+    }
+    if rank, err := tree.TryRank(newKey(IntKey(4))); err != nil || rank != 4 {
+        t.Fatalf("TryRank(4) = %d, %v, want 4", rank, err)
+    }
+    seq, err := tree.TryRange(newKey(IntKey(2)), newKey(IntKey(5)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    count := 0
+    for range seq {
+        count++
+    }
+    if count != 4 {
+        t.Fatalf("TryRange(2, 5) yielded %d items, want 4", count)
+    }
+
+    synced := NewSyncRbTree()
+    synced.Insert(newKey(IntKey(1)), 1)
+    if _, err := synced.TryFrom(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("SyncRbTree.TryFrom of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if _, _, err := synced.TryCeiling(key); err != ErrKeyTypeMismatch {
+        t.Fatalf("SyncRbTree.TryCeiling of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+
+    keys := func(yield func(RbKey, interface{}) bool) {
+        _ = yield(newKey(IntKey(1)), 1) && yield(newKey(StringKey("2")), 2)
+    }
+    if err := NewRbTree().BuildFromSorted(keys); err != ErrKeyTypeMismatch {
+        t.Fatalf("BuildFromSorted of mixed key types = %v, want ErrKeyTypeMismatch", err)
+    }
+}
+
+func TestIterationPanicSentinel(t *testing.T) {
+    tree := NewRbTree()
+    tree.Insert(newKey(IntKey(1)), 1)
+
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        panic(42)
+    })
+    if _, err := iterator.All(); !errors.Is(err, ErrIterationPanicked) {
+        t.Fatalf("All() with a panicking callback = %v, want ErrIterationPanicked", err)
+    }
+    if errors.Is(ErrKeyTypeMismatch, ErrIterationPanicked) {
+        t.Fatalf("ErrKeyTypeMismatch matches ErrIterationPanicked")
+    }
+}
@@ -0,0 +1,168 @@
+package rbt
+
+import (
+    "iter"
+    "reflect"
+)
+
+// MultiRbTree structure is the red-black tree keeping every inserted key and value pair,
+// the values of the same key are kept in their insertion order
+type MultiRbTree struct {
+    tree *RbTree
+    count int
+}
+
+// NewMultiRbTree creates a new MultiRbTree and returns its address
+func NewMultiRbTree() *MultiRbTree {
+    return &MultiRbTree{
+        tree: NewRbTreeWithEvents(appendValues, nil),
+    }
+}
+
+// appendValues is the insert event of the inner tree appending the new values of a key to its existing values
+func appendValues(key RbKey, oldValue interface{}, newValue interface{}) interface{} {
+    return append(oldValue.([]interface{}), newValue.([]interface{})...)
+}
+
+// Count returns the count of the key and value pairs stored.
+func (tree *MultiRbTree) Count() int {
+    return tree.count
+}
+
+// KeyCount returns the count of the distinct keys stored.
+func (tree *MultiRbTree) KeyCount() int {
+    return tree.tree.Count()
+}
+
+// IsEmpty returns if the tree has any key.
+func (tree *MultiRbTree) IsEmpty() bool {
+    return tree.tree.IsEmpty()
+}
+
+// Insert adds the given key and value pair into the tree after the existing values of the key
+func (tree *MultiRbTree) Insert(key RbKey, value interface{}) {
+    if key != nil {
+        tree.tree.Insert(key, []interface{}{value})
+        tree.count++
+    }
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *MultiRbTree) Exists(key RbKey) bool {
+    return tree.tree.Exists(key)
+}
+
+// Get returns the first inserted value of the key and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *MultiRbTree) Get(key RbKey) (interface{}, bool) {
+    if values, ok := tree.tree.Get(key); ok {
+        return values.([]interface{})[0], true
+    }
+    return nil, false
+}
+
+// GetAll returns a copy of the values of the key in their insertion order, nil if key not found
+func (tree *MultiRbTree) GetAll(key RbKey) []interface{} {
+    if values, ok := tree.tree.Get(key); ok {
+        return append([]interface{}(nil), values.([]interface{})...)
+    }
+    return nil
+}
+
+// CountKey returns the count of the values stored with the key
+func (tree *MultiRbTree) CountKey(key RbKey) int {
+    if values, ok := tree.tree.Get(key); ok {
+        return len(values.([]interface{}))
+    }
+    return 0
+}
+
+// DeleteOne deletes the first inserted pair of the key having a value equal to the given value
+// compared with reflect.DeepEqual, so the uncomparable values like slices and maps can be matched,
+// returns 'true' if a pair is deleted
+func (tree *MultiRbTree) DeleteOne(key RbKey, value interface{}) bool {
+    if key == nil {
+        return false
+    }
+
+    node := tree.tree.find(key)
+    if node == nil {
+        return false
+    }
+
+    values := node.value.([]interface{})
+    for i, v := range values {
+        if reflect.DeepEqual(v, value) {
+            if len(values) == 1 {
+                tree.tree.Delete(key)
+            } else {
+                node.value = append(values[:i:i], values[i+1:]...)
+                tree.tree.version++
+            }
+            tree.count--
+            return true
+        }
+    }
+    return false
+}
+
+// DeleteAll deletes all the pairs of the key, returns the count of the deleted pairs
+func (tree *MultiRbTree) DeleteAll(key RbKey) int {
+    count := tree.CountKey(key)
+    if count > 0 {
+        tree.tree.Delete(key)
+        tree.count -= count
+    }
+    return count
+}
+
+// All returns a sequence of all pairs of the MultiRbTree in ascending order of the keys
+func (tree *MultiRbTree) All() iter.Seq2[RbKey, interface{}] {
+    return tree.pairs(tree.tree.All(), false)
+}
+
+// Backward returns a sequence of all pairs of the MultiRbTree in descending order of the keys,
+// the values of the same key are yielded in reverse insertion order
+func (tree *MultiRbTree) Backward() iter.Seq2[RbKey, interface{}] {
+    return tree.pairs(tree.tree.Backward(), true)
+}
+
+// Range returns a sequence of the pairs of the MultiRbTree in ascending order that the key of the pair
+// is greater or equal to loKey and less or equal to hiKey, a nil key leaves that side of the range open
+func (tree *MultiRbTree) Range(loKey RbKey, hiKey RbKey) iter.Seq2[RbKey, interface{}] {
+    return tree.pairs(tree.tree.Range(loKey, hiKey), false)
+}
+
+// From returns a sequence of the pairs of the MultiRbTree in ascending order that the key of the pair
+// is greater or equal to the given key
+func (tree *MultiRbTree) From(key RbKey) iter.Seq2[RbKey, interface{}] {
+    return tree.pairs(tree.tree.From(key), false)
+}
+
+// Until returns a sequence of the pairs of the MultiRbTree in ascending order that the key of the pair
+// is less than the given key
+func (tree *MultiRbTree) Until(key RbKey) iter.Seq2[RbKey, interface{}] {
+    return tree.pairs(tree.tree.Until(key), false)
+}
+
+// pairs expands the value lists of the inner tree sequence into key and value pairs.
+// The sequence panics with ErrEnumeratorModified if the tree gets modified while iterating.
+func (tree *MultiRbTree) pairs(seq iter.Seq2[RbKey, interface{}], reverse bool) iter.Seq2[RbKey, interface{}] {
+    return func(yield func(RbKey, interface{}) bool) {
+        version := tree.tree.version
+        for key, value := range seq {
+            values := value.([]interface{})
+            for i := range values {
+                if version != tree.tree.version {
+                    panic(ErrEnumeratorModified)
+                }
+                if reverse {
+                    i = len(values) - 1 - i
+                }
+                if !yield(key, values[i]) {
+                    return
+                }
+            }
+        }
+    }
+}
@@ -0,0 +1,105 @@
+package rbt
+
+import (
+    "testing"
+)
+
+func TestMultiRbTree(t *testing.T) {
+    tree := NewMultiRbTree()
+    for i := 0; i < 30; i++ {
+        key := IntKey(i % 10)
+        tree.Insert(&key, i)
+    }
+
+    if tree.Count() != 30 || tree.KeyCount() != 10 {
+        t.Fatalf("Count() = %d, KeyCount() = %d, want 30, 10", tree.Count(), tree.KeyCount())
+    }
+
+    key := IntKey(3)
+    if values := tree.GetAll(&key); len(values) != 3 || values[0] != 3 || values[1] != 13 || values[2] != 23 {
+        t.Fatalf("GetAll(3) = %v, want [3 13 23]", values)
+    }
+    if value, ok := tree.Get(&key); !ok || value != 3 {
+        t.Fatalf("Get(3) = %v, %v, want 3, true", value, ok)
+    }
+
+    if !tree.DeleteOne(&key, 13) || tree.DeleteOne(&key, 13) {
+        t.Fatalf("DeleteOne(3, 13) did not delete exactly one pair")
+    }
+    if values := tree.GetAll(&key); len(values) != 2 || values[0] != 3 || values[1] != 23 {
+        t.Fatalf("GetAll(3) = %v, want [3 23]", values)
+    }
+    tree.DeleteOne(&key, 3)
+    tree.DeleteOne(&key, 23)
+    if tree.Exists(&key) || tree.CountKey(&key) != 0 || tree.KeyCount() != 9 {
+        t.Fatalf("key 3 exists after deleting all its values")
+    }
+
+    key = IntKey(5)
+    if count := tree.DeleteAll(&key); count != 3 || tree.Count() != 24 {
+        t.Fatalf("DeleteAll(5) = %d, Count() = %d, want 3, 24", count, tree.Count())
+    }
+
+    var values []int
+    for k, v := range tree.All() {
+        if int(*k.(*IntKey)) != v.(int) % 10 {
+            t.Fatalf("All yielded key %v with value %v", k, v)
+        }
+        values = append(values, v.(int))
+    }
+    expected := []int{0, 10, 20, 1, 11, 21, 2, 12, 22, 4, 14, 24, 6, 16, 26, 7, 17, 27, 8, 18, 28, 9, 19, 29}
+    if len(values) != len(expected) {
+        t.Fatalf("All yielded %v, want %v", values, expected)
+    }
+    for i := range expected {
+        if values[i] != expected[i] {
+            t.Fatalf("All yielded %v, want %v", values, expected)
+        }
+    }
+
+    values = values[:0]
+    for _, v := range tree.Backward() {
+        values = append(values, v.(int))
+    }
+    for i := range expected {
+        if values[len(values) - 1 - i] != expected[i] {
+            t.Fatalf("Backward yielded %v, want reverse of %v", values, expected)
+        }
+    }
+
+    lo, hi := IntKey(2), IntKey(4)
+    values = values[:0]
+    for _, v := range tree.Range(&hi, &lo) {
+        values = append(values, v.(int))
+    }
+    if len(values) != 6 || values[0] != 2 || values[5] != 24 {
+        t.Fatalf("Range(2, 4) yielded %v", values)
+    }
+
+    defer func() {
+        if r := recover(); r != ErrEnumeratorModified {
+            t.Fatalf("modifying while iterating recovered %v, want %v", r, ErrEnumeratorModified)
+        }
+    }()
+    for k, v := range tree.All() {
+        tree.DeleteOne(k, v)
+    }
+}
+
+func TestMultiRbTreeUncomparableValues(t *testing.T) {
+    tree := NewMultiRbTree()
+    key := StringKey("k")
+    tree.Insert(&key, []byte("a"))
+    tree.Insert(&key, map[string]int{"b": 1})
+    tree.Insert(&key, []byte("c"))
+
+    if tree.DeleteOne(&key, []byte("x")) {
+        t.Fatalf("DeleteOne deleted a missing slice value")
+    }
+    if !tree.DeleteOne(&key, []byte("c")) || !tree.DeleteOne(&key, map[string]int{"b": 1}) {
+        t.Fatalf("DeleteOne did not delete the uncomparable values")
+    }
+    if values := tree.GetAll(&key); len(values) != 1 || string(values[0].([]byte)) != "a" || tree.Count() != 1 {
+        t.Fatalf("GetAll() = %v, want [a]", values)
+    }
+}
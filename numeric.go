@@ -0,0 +1,165 @@
+package rbt
+
+import (
+    "math"
+    "reflect"
+)
+
+// numberKind is the kind of the value of a numeric key
+type numberKind byte
+
+const (
+    signedNumber numberKind = iota
+    unsignedNumber
+    floatNumber
+)
+
+// number structure holds the value of a numeric key without loss
+type number struct {
+    kind numberKind
+    i int64
+    u uint64
+    f float64
+}
+
+// numericKeyTypes are the key types comparable with each other by their numeric values
+var numericKeyTypes = map[reflect.Type]bool {
+    reflect.TypeOf((*NilKey)(nil)): true,
+    reflect.TypeOf((*ByteKey)(nil)): true,
+    reflect.TypeOf((*IntKey)(nil)): true,
+    reflect.TypeOf((*Int8Key)(nil)): true,
+    reflect.TypeOf((*Int16Key)(nil)): true,
+    reflect.TypeOf((*Int32Key)(nil)): true,
+    reflect.TypeOf((*Int64Key)(nil)): true,
+    reflect.TypeOf((*UintKey)(nil)): true,
+    reflect.TypeOf((*Uint8Key)(nil)): true,
+    reflect.TypeOf((*Uint16Key)(nil)): true,
+    reflect.TypeOf((*Uint32Key)(nil)): true,
+    reflect.TypeOf((*Uint64Key)(nil)): true,
+    reflect.TypeOf((*Float32Key)(nil)): true,
+    reflect.TypeOf((*Float64Key)(nil)): true,
+}
+
+// numberOf returns the value of the key if it is a numeric key
+func numberOf(key RbKey) (number, bool) {
+    switch k := key.(type) {
+    case *ByteKey:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *IntKey:
+        return number{kind: signedNumber, i: int64(*k)}, true
+    case *Int8Key:
+        return number{kind: signedNumber, i: int64(*k)}, true
+    case *Int16Key:
+        return number{kind: signedNumber, i: int64(*k)}, true
+    case *Int32Key:
+        return number{kind: signedNumber, i: int64(*k)}, true
+    case *Int64Key:
+        return number{kind: signedNumber, i: int64(*k)}, true
+    case *UintKey:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *Uint8Key:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *Uint16Key:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *Uint32Key:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *Uint64Key:
+        return number{kind: unsignedNumber, u: uint64(*k)}, true
+    case *Float32Key:
+        return number{kind: floatNumber, f: float64(*k)}, true
+    case *Float64Key:
+        return number{kind: floatNumber, f: float64(*k)}, true
+    }
+    return number{}, false
+}
+
+// compareNumericKey compares the value of a numeric key with the given key,
+// NilKey is less than all numeric keys. Panics with ErrKeyTypeMismatch if the key is not a numeric key.
+func compareNumericKey(a number, key RbKey) KeyComparison {
+    if key == nil {
+        return KeyIsGreater
+    }
+    if _, ok := key.(*NilKey); ok {
+        return KeyIsGreater
+    }
+
+    b, ok := numberOf(key)
+    if !ok {
+        panic(ErrKeyTypeMismatch)
+    }
+    return compareNumbers(a, b)
+}
+
+// compareNumbers compares the numbers by their exact mathematical values,
+// NaN is less than all other numbers and equal to NaN
+func compareNumbers(a, b number) KeyComparison {
+    switch a.kind {
+    case signedNumber:
+        switch b.kind {
+        case signedNumber:
+            return compareOrdered(a.i, b.i)
+        case unsignedNumber:
+            return compareSignedToUnsigned(a.i, b.u)
+        default:
+            return -compareFloatToSigned(b.f, a.i)
+        }
+    case unsignedNumber:
+        switch b.kind {
+        case signedNumber:
+            return -compareSignedToUnsigned(b.i, a.u)
+        case unsignedNumber:
+            return compareOrdered(a.u, b.u)
+        default:
+            return -compareFloatToUnsigned(b.f, a.u)
+        }
+    default:
+        switch b.kind {
+        case signedNumber:
+            return compareFloatToSigned(a.f, b.i)
+        case unsignedNumber:
+            return compareFloatToUnsigned(a.f, b.u)
+        default:
+            return compareOrdered(a.f, b.f)
+        }
+    }
+}
+
+// compareSignedToUnsigned compares an int64 with an uint64
+func compareSignedToUnsigned(i int64, u uint64) KeyComparison {
+    if i < 0 {
+        return KeyIsLess
+    }
+    return compareOrdered(uint64(i), u)
+}
+
+// compareFloatToSigned compares a float64 with an int64 without rounding the int64
+func compareFloatToSigned(f float64, i int64) KeyComparison {
+    switch {
+    case math.IsNaN(f) || f < math.MinInt64:
+        return KeyIsLess
+    case f >= -math.MinInt64:
+        return KeyIsGreater
+    }
+
+    t := math.Trunc(f)
+    if cmp := compareOrdered(int64(t), i); cmp != KeysAreEqual {
+        return cmp
+    }
+    return compareOrdered(f - t, 0)
+}
+
+// compareFloatToUnsigned compares a float64 with an uint64 without rounding the uint64
+func compareFloatToUnsigned(f float64, u uint64) KeyComparison {
+    switch {
+    case math.IsNaN(f) || f < 0:
+        return KeyIsLess
+    case f >= math.MaxUint64:
+        return KeyIsGreater
+    }
+
+    t := math.Trunc(f)
+    if cmp := compareOrdered(uint64(t), u); cmp != KeysAreEqual {
+        return cmp
+    }
+    return compareOrdered(f - t, 0)
+}
@@ -0,0 +1,110 @@
+package rbt
+
+import (
+    "math"
+    "testing"
+)
+
+func TestNumericKeyOrdering(t *testing.T) {
+    ordered := []RbKey{
+        &NilKey{},
+        newKey(Float64Key(math.NaN())),
+        newKey(Float64Key(math.Inf(-1))),
+        newKey(Float64Key(-1e19)),
+        newKey(Int64Key(math.MinInt64)),
+        newKey(Int64Key(math.MinInt64 + 1)),
+        newKey(Int32Key(math.MinInt32)),
+        newKey(Float32Key(-1.5)),
+        newKey(Int8Key(-1)),
+        newKey(Float64Key(-0.5)),
+        newKey(UintKey(0)),
+        newKey(Float32Key(0.25)),
+        newKey(ByteKey(1)),
+        newKey(Float64Key(1.5)),
+        newKey(Uint8Key(200)),
+        newKey(Int16Key(300)),
+        newKey(Uint32Key(math.MaxUint32)),
+        newKey(Int64Key(math.MaxInt64 - 1)),
+        newKey(IntKey(math.MaxInt64)),
+        newKey(Float64Key(1 << 63)),
+        newKey(Uint64Key(1 << 63 + 1)),
+        newKey(Uint64Key(math.MaxUint64 - 1)),
+        newKey(Uint64Key(math.MaxUint64)),
+        newKey(Float64Key(1 << 64)),
+        newKey(Float32Key(float32(math.Inf(1)))),
+    }
+
+    for i, a := range ordered {
+        for j, b := range ordered {
+            want := compareOrdered(i, j)
+            if got := a.ComparedTo(b); got != want {
+                t.Fatalf("%d.ComparedTo(%d) = %v, want %v", i, j, got, want)
+            }
+        }
+    }
+
+    equal := []RbKey{newKey(Int8Key(7)), newKey(Uint16Key(7)), newKey(Float32Key(7)), newKey(Float64Key(7)), newKey(IntKey(7))}
+    for _, a := range equal {
+        for _, b := range equal {
+            if a.ComparedTo(b) != KeysAreEqual {
+                t.Fatalf("%T(7) not equal to %T(7)", a, b)
+            }
+        }
+    }
+
+    if newKey(Float64Key(math.MaxInt64)).ComparedTo(newKey(Int64Key(math.MaxInt64))) != KeyIsGreater {
+        t.Fatalf("float64(MaxInt64) rounded to MaxInt64")
+    }
+    if newKey(Int64Key(1 << 53 + 1)).ComparedTo(newKey(Float64Key(1 << 53))) != KeyIsGreater {
+        t.Fatalf("2^53 + 1 rounded to 2^53")
+    }
+}
+
+func TestHeterogeneousNumericTree(t *testing.T) {
+    tree := NewRbTree()
+    for i := 0; i < 100; i++ {
+        var key RbKey
+        switch i % 3 {
+        case 0:
+            key = newKey(Int32Key(i))
+        case 1:
+            key = newKey(Int64Key(i))
+        default:
+            key = newKey(Uint64Key(i))
+        }
+        if err := tree.TryInsert(key, i); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if err := tree.TryInsert(&NilKey{}, -1); err != nil {
+        t.Fatal(err)
+    }
+    if err := tree.TryInsert(newKey(StringKey("a")), 0); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsert of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    checkRbTree(t, tree.root)
+
+    expected := -1
+    for _, value := range tree.All() {
+        if value != expected {
+            t.Fatalf("value %v out of order, want %d", value, expected)
+        }
+        expected++
+    }
+
+    if value, ok := tree.Get(newKey(Float64Key(42))); !ok || value != 42 {
+        t.Fatalf("Get(42.0) = %v, %v", value, ok)
+    }
+    tree.Delete(newKey(UintKey(43)))
+    if tree.Exists(newKey(Int64Key(43))) {
+        t.Fatalf("key 43 not deleted")
+    }
+    if n := tree.CountBetween(newKey(Uint8Key(10)), newKey(Float32Key(19.5))); n != 10 {
+        t.Fatalf("CountBetween(10, 19.5) = %d, want 10", n)
+    }
+
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {})
+    if _, err := iterator.LessThan(newKey(StringKey("a"))); err != ErrKeyTypeMismatch {
+        t.Fatalf("LessThan of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+}
@@ -0,0 +1,66 @@
+package rbt
+
+// Rank returns the count of the keys in the tree strictly less than the given key
+func (tree *RbTree) Rank(key RbKey) int {
+    if key == nil {
+        return 0
+    }
+    return rank(tree.root, key, false, tree.compare)
+}
+
+// Select returns the key and value at the given zero based position in the sorted order of the tree,
+// otherwise returns nil if the position is out of range
+func (tree *RbTree) Select(index int) (RbKey, interface{}) {
+    if index < 0 || index >= size(tree.root) {
+        return nil, nil
+    }
+
+    node := tree.root
+    for node != nil {
+        leftSize := size(node.left)
+        switch {
+        case index < leftSize:
+            node = node.left
+        case index > leftSize:
+            index -= leftSize + 1
+            node = node.right
+        default:
+            return node.key, node.value
+        }
+    }
+    return nil, nil
+}
+
+// CountBetween returns the count of the keys in the tree that are
+// greater or equal to loKey and less or equal to hiKey
+func (tree *RbTree) CountBetween(loKey RbKey, hiKey RbKey) int {
+    if loKey == nil || hiKey == nil || tree.root == nil {
+        return 0
+    }
+    if tree.compareKeys(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return rank(tree.root, hiKey, true, tree.compare) - rank(tree.root, loKey, false, tree.compare)
+}
+
+// rank returns the count of the keys in the subtree rooted at node less than the given key,
+// the keys equal to the given key are also counted if inclusive
+func rank(node *rbNode, key RbKey, inclusive bool, compare KeyComparator) int {
+    result := 0
+    for node != nil {
+        switch compareKeys(compare, key, node.key) {
+        case KeyIsLess:
+            node = node.left
+        case KeyIsGreater:
+            result += size(node.left) + 1
+            node = node.right
+        default:
+            result += size(node.left)
+            if inclusive {
+                result++
+            }
+            return result
+        }
+    }
+    return result
+}
@@ -0,0 +1,90 @@
+package rbt
+
+// linked returns 'true' if the parent links of the nodes are valid.
+// The links are valid only while the tree owns all of its nodes: a tree frozen by Snapshot
+// or by a set operation and a tree created by ToRbTree share their nodes with other trees,
+// and the links of the shared nodes are never written. Such a tree falls back to the searches
+// from the root until all of its shared nodes get copied by the modifications. The trees created by
+// Split, Join or a set operation do not track their shared nodes, they fall back to the searches
+// from the root until BuildFromSorted rebuilds their nodes.
+func (tree *RbTree) linked() bool {
+    return tree.shared == 0 && !tree.unlinked
+}
+
+// linkChildren sets the node as the parent of its children
+func linkChildren(node *rbNode) {
+    if node.left != nil {
+        node.left.parent = node
+    }
+    if node.right != nil {
+        node.right.parent = node
+    }
+}
+
+// successor returns the node with the smallest key greater than the key of the node using the parent links
+func successor(node *rbNode) *rbNode {
+    if node.right != nil {
+        return min(node.right)
+    }
+    for node.parent != nil && node == node.parent.right {
+        node = node.parent
+    }
+    return node.parent
+}
+
+// predecessor returns the node with the largest key less than the key of the node using the parent links
+func predecessor(node *rbNode) *rbNode {
+    if node.left != nil {
+        return max(node.left)
+    }
+    for node.parent != nil && node == node.parent.left {
+        node = node.parent
+    }
+    return node.parent
+}
+
+// Successor returns the smallest key in the tree strictly greater than key.
+// On a tree maintaining its parent links the neighbour is reached from the located node
+// following the links, otherwise it is searched from the root.
+func (tree *RbTree) Successor(key RbKey) (RbKey, interface{}) {
+    if key == nil || tree.root == nil {
+        return nil, nil
+    }
+
+    var node *rbNode
+    if !tree.linked() {
+        node = higher(tree.root, key, tree.compare)
+    } else if node = floor(tree.root, key, tree.compare); node != nil {
+        node = successor(node)
+    } else {
+        node = min(tree.root)
+    }
+
+    if node != nil {
+        return node.key, node.value
+    }
+    return nil, nil
+}
+
+// Predecessor returns the largest key in the tree strictly less than key.
+// On a tree maintaining its parent links the neighbour is reached from the located node
+// following the links, otherwise it is searched from the root.
+func (tree *RbTree) Predecessor(key RbKey) (RbKey, interface{}) {
+    if key == nil || tree.root == nil {
+        return nil, nil
+    }
+
+    var node *rbNode
+    if !tree.linked() {
+        node = lower(tree.root, key, tree.compare)
+    } else if node = ceiling(tree.root, key, tree.compare); node != nil {
+        node = predecessor(node)
+    } else {
+        node = max(tree.root)
+    }
+
+    if node != nil {
+        return node.key, node.value
+    }
+    return nil, nil
+}
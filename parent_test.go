@@ -0,0 +1,138 @@
+package rbt
+
+import (
+    "math/rand"
+    "testing"
+)
+
+// checkParents validates the parent links of the subtree
+func checkParents(t *testing.T, node *rbNode, parent *rbNode) {
+    if node == nil {
+        return
+    }
+    if node.parent != parent {
+        t.Fatalf("parent link of %v is broken", node.key)
+    }
+    checkParents(t, node.left, node)
+    checkParents(t, node.right, node)
+}
+
+func TestParentLinks(t *testing.T) {
+    tree := NewRbTreeWithAllocation(ArenaAllocation)
+    r := rand.New(rand.NewSource(24))
+    for i := 0; i < 20000; i++ {
+        key := newKey(IntKey(r.Intn(4000)))
+        if r.Intn(3) == 0 {
+            tree.Delete(key)
+        } else {
+            tree.Insert(key, i)
+        }
+        if i % 1000 == 0 {
+            checkParents(t, tree.root, nil)
+        }
+    }
+    checkRbTree(t, tree.root)
+    checkParents(t, tree.root, nil)
+
+    for node := min(tree.root); node != nil; node = successor(node) {
+        if next := higher(tree.root, node.key, nil); successor(node) != next {
+            t.Fatalf("successor of %v differs from higher", node.key)
+        }
+        if prev := lower(tree.root, node.key, nil); predecessor(node) != prev {
+            t.Fatalf("predecessor of %v differs from lower", node.key)
+        }
+    }
+
+    keys := make([]RbKey, 0, 100)
+    for i := 0; i < 100; i++ {
+        keys = append(keys, newKey(IntKey(i * 2)))
+    }
+    built := NewRbTree()
+    built.Insert(newKey(IntKey(-1)), nil)
+    left, _ := built.Split(newKey(IntKey(0)))
+    if left.linked() {
+        t.Fatalf("tree created by Split is linked")
+    }
+    if err := left.BuildFromSorted(func(yield func(RbKey, interface{}) bool) {
+        for _, key := range keys {
+            if !yield(key, nil) {
+                return
+            }
+        }
+    }); err != nil {
+        t.Fatal(err)
+    }
+    if !left.linked() {
+        t.Fatalf("tree built from sorted keys is not linked")
+    }
+    checkParents(t, left.root, nil)
+
+    left.Snapshot()
+    if left.linked() {
+        t.Fatalf("frozen tree is linked")
+    }
+    if err := left.BuildFromSorted(left.All()); err != nil {
+        t.Fatal(err)
+    }
+    if !left.linked() {
+        t.Fatalf("frozen tree rebuilt from sorted keys is not linked")
+    }
+    checkParents(t, left.root, nil)
+}
+
+func TestSuccessorPredecessor(t *testing.T) {
+    tree := NewRbTree()
+    if key, _ := tree.Successor(newKey(IntKey(0))); key != nil {
+        t.Fatalf("Successor on an empty tree = %v", key)
+    }
+    for i := 0; i < 100; i += 2 {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+
+    if key, value := tree.Successor(newKey(IntKey(10))); *key.(*IntKey) != 12 || value != 12 {
+        t.Fatalf("Successor(10) = %v, %v, want 12", key, value)
+    }
+    if key, _ := tree.Successor(newKey(IntKey(11))); *key.(*IntKey) != 12 {
+        t.Fatalf("Successor(11) = %v, want 12", key)
+    }
+    if key, _ := tree.Predecessor(newKey(IntKey(10))); *key.(*IntKey) != 8 {
+        t.Fatalf("Predecessor(10) = %v, want 8", key)
+    }
+    if key, _ := tree.Successor(newKey(IntKey(98))); key != nil {
+        t.Fatalf("Successor(98) = %v, want nil", key)
+    }
+    if key, _ := tree.Predecessor(newKey(IntKey(0))); key != nil {
+        t.Fatalf("Predecessor(0) = %v, want nil", key)
+    }
+    if key, _ := tree.Successor(newKey(IntKey(-5))); *key.(*IntKey) != 0 {
+        t.Fatalf("Successor(-5) = %v, want 0", key)
+    }
+    if key, _ := tree.Predecessor(newKey(IntKey(500))); *key.(*IntKey) != 98 {
+        t.Fatalf("Predecessor(500) = %v, want 98", key)
+    }
+
+    cursor := tree.NewCursor()
+    count, want := 0, IntKey(0)
+    for ok := cursor.SeekFirst(); ok; ok = cursor.Next() {
+        if key := *cursor.Key().(*IntKey); key != want {
+            t.Fatalf("cursor at %d, want %d", key, want)
+        }
+        if count++; want == 48 {
+            tree.Delete(newKey(IntKey(50)))
+            want += 2
+        }
+        want += 2
+    }
+    if count != 49 {
+        t.Fatalf("cursor stepped on %d keys, want 49", count)
+    }
+
+    tree.Snapshot()
+    count = 0
+    for ok := cursor.SeekLast(); ok; ok = cursor.Prev() {
+        count++
+    }
+    if count != 49 {
+        t.Fatalf("cursor stepped back on %d keys of the frozen tree, want 49", count)
+    }
+}
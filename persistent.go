@@ -0,0 +1,194 @@
+package rbt
+
+import (
+    "reflect"
+    "sync/atomic"
+)
+
+// PersistentRbTree structure is the immutable variant of RbTree.
+// Insert and Delete operations do not modify the tree but return a new tree
+// sharing the unchanged nodes with the original one by path copying.
+type PersistentRbTree struct {
+    root *rbNode
+    count int
+    onInsert InsertEvent
+    onDelete DeleteEvent
+    compare KeyComparator
+    keyType reflect.Type
+}
+
+// lastGeneration is the last generation given to a copy-on-write modification
+var lastGeneration uint32
+
+// nextGeneration returns a new unique generation
+func nextGeneration() uint32 {
+    return atomic.AddUint32(&lastGeneration, 1)
+}
+
+// NewPersistentRbTree creates a new empty PersistentRbTree and returns its address
+func NewPersistentRbTree() *PersistentRbTree {
+    return &PersistentRbTree{}
+}
+
+// NewPersistentRbTreeWithEvents creates a new empty PersistentRbTree assigning its insert and delete events
+// and returns its address
+func NewPersistentRbTreeWithEvents(onInsert InsertEvent, onDelete DeleteEvent) *PersistentRbTree {
+    return &PersistentRbTree{
+        onInsert: onInsert,
+        onDelete: onDelete,
+    }
+}
+
+// Snapshot returns a point-in-time view of the tree in O(1).
+// The nodes of the tree are shared with the snapshot, so the later modifications
+// on the tree copy the nodes they change and never become visible in the snapshot.
+// A modification copies each shared node on its path once, the copies are owned by the tree
+// and changed in place afterwards. Until all of the shared nodes are copied or BuildFromSorted
+// rebuilds the tree, Successor, Predecessor and the cursors search from the root instead of
+// following the parent links.
+func (tree *RbTree) Snapshot() *PersistentRbTree {
+    tree.freeze()
+    return &PersistentRbTree{
+        root: tree.root,
+        count: tree.count,
+        onInsert: tree.onInsert,
+        onDelete: tree.onDelete,
+        compare: tree.compare,
+        keyType: tree.keyType,
+    }
+}
+
+// freeze marks all the nodes of the tree as shared,
+// so the later modifications on the tree copy the nodes they change
+func (tree *RbTree) freeze() {
+    tree.gen = nextGeneration()
+    tree.shared = tree.count
+}
+
+// ToRbTree returns a RbTree starting with the items of the persistent tree in O(1),
+// the modifications on the returned tree do not change the persistent tree
+func (tree *PersistentRbTree) ToRbTree() *RbTree {
+    return &RbTree{
+        root: tree.root,
+        count: tree.count,
+        gen: nextGeneration(),
+        shared: tree.count,
+        onInsert: tree.onInsert,
+        onDelete: tree.onDelete,
+        compare: tree.compare,
+        keyType: tree.keyType,
+    }
+}
+
+// Count returns if count of the nodes stored.
+func (tree *PersistentRbTree) Count() int {
+    return tree.count
+}
+
+// IsEmpty returns if the tree has any node.
+func (tree *PersistentRbTree) IsEmpty() bool {
+    return tree.root == nil
+}
+
+// Min returns the smallest key in the tree.
+func (tree *PersistentRbTree) Min() (RbKey, interface{}) {
+    if tree.root != nil {
+        result := min(tree.root)
+        return result.key, result.value
+    }
+    return nil, nil
+}
+
+// Max returns the largest key in the tree.
+func (tree *PersistentRbTree) Max() (RbKey, interface{}) {
+    if tree.root != nil {
+        result := max(tree.root)
+        return result.key, result.value
+    }
+    return nil, nil
+}
+
+// Floor returns the largest key in the tree less than or equal to key
+func (tree *PersistentRbTree) Floor(key RbKey) (RbKey, interface{}) {
+    if key != nil {
+        if node := floor(tree.root, key, tree.compare); node != nil {
+            return node.key, node.value
+        }
+    }
+    return nil, nil
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to key
+func (tree *PersistentRbTree) Ceiling(key RbKey) (RbKey, interface{}) {
+    if key != nil {
+        if node := ceiling(tree.root, key, tree.compare); node != nil {
+            return node.key, node.value
+        }
+    }
+    return nil, nil
+}
+
+// Get returns the stored value if key found and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *PersistentRbTree) Get(key RbKey) (interface{}, bool) {
+    if key != nil {
+        if node := lookup(tree.root, key, tree.compare); node != nil {
+            return node.value, true
+        }
+    }
+    return nil, false
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *PersistentRbTree) Exists(key RbKey) bool {
+    return key != nil && lookup(tree.root, key, tree.compare) != nil
+}
+
+// Insert returns a new tree containing the given key and value in addition to the items of the tree
+func (tree *PersistentRbTree) Insert(key RbKey, value interface{}) *PersistentRbTree {
+    if key == nil {
+        return tree
+    }
+
+    work := tree.modify()
+    work.Insert(key, value)
+    return tree.persist(work)
+}
+
+// Delete returns a new tree containing the items of the tree except the given key,
+// returns the tree itself if the key does not exist
+func (tree *PersistentRbTree) Delete(key RbKey) *PersistentRbTree {
+    if key == nil || lookup(tree.root, key, tree.compare) == nil {
+        return tree
+    }
+
+    work := tree.modify()
+    work.Delete(key)
+    return tree.persist(work)
+}
+
+// modify creates the tree modifying the items of the persistent tree with a new generation,
+// so the modification copies the path to the key and leaves the nodes of the persistent tree unchanged
+func (tree *PersistentRbTree) modify() *RbTree {
+    return &RbTree{
+        root: tree.root,
+        count: tree.count,
+        gen: nextGeneration(),
+        shared: tree.count,
+        onInsert: tree.onInsert,
+        onDelete: tree.onDelete,
+        compare: tree.compare,
+    }
+}
+
+// persist creates the persistent tree holding the items of the modified tree
+func (tree *PersistentRbTree) persist(work *RbTree) *PersistentRbTree {
+    return &PersistentRbTree{
+        root: work.root,
+        count: work.count,
+        onInsert: tree.onInsert,
+        onDelete: tree.onDelete,
+        compare: tree.compare,
+        keyType: tree.keyType,
+    }
+}
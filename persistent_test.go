@@ -0,0 +1,159 @@
+package rbt
+
+import (
+    "math/rand"
+    "sync"
+    "testing"
+)
+
+// checkContents validates that the persistent tree stores exactly the expected items
+func checkContents(t *testing.T, tree *PersistentRbTree, expected map[int]int) {
+    checkRbTree(t, tree.root)
+    if tree.Count() != len(expected) || size(tree.root) != len(expected) {
+        t.Fatalf("Count() = %d, size = %d, want %d", tree.Count(), size(tree.root), len(expected))
+    }
+    for k, v := range expected {
+        key := IntKey(k)
+        if value, ok := tree.Get(&key); !ok || value != v {
+            t.Fatalf("Get(%d) = %v, %v, want %d", k, value, ok, v)
+        }
+    }
+}
+
+func copyMap(m map[int]int) map[int]int {
+    result := make(map[int]int, len(m))
+    for k, v := range m {
+        result[k] = v
+    }
+    return result
+}
+
+func TestSnapshot(t *testing.T) {
+    tree := NewRbTree()
+    expected := make(map[int]int)
+
+    var snapshots []*PersistentRbTree
+    var contents []map[int]int
+
+    rnd := rand.New(rand.NewSource(1))
+    for i := 0; i < 20000; i++ {
+        key := IntKey(rnd.Intn(1000))
+        if rnd.Intn(3) == 0 {
+            tree.Delete(&key)
+            delete(expected, int(key))
+        } else {
+            tree.Insert(&key, i)
+            expected[int(key)] = i
+        }
+        if i % 2000 == 0 {
+            snapshots = append(snapshots, tree.Snapshot())
+            contents = append(contents, copyMap(expected))
+        }
+    }
+
+    checkRbTree(t, tree.root)
+    if tree.Count() != len(expected) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(expected))
+    }
+    for i, snapshot := range snapshots {
+        checkContents(t, snapshot, contents[i])
+    }
+
+    copied := snapshots[0].ToRbTree()
+    for k := range contents[0] {
+        key := IntKey(k)
+        copied.Delete(&key)
+    }
+    if !copied.IsEmpty() || copied.Count() != 0 {
+        t.Fatalf("Count() = %d after deleting all keys", copied.Count())
+    }
+    checkContents(t, snapshots[0], contents[0])
+}
+
+func TestPersistentRbTree(t *testing.T) {
+    tree := NewPersistentRbTree()
+    expected := make(map[int]int)
+
+    var versions []*PersistentRbTree
+    var contents []map[int]int
+
+    rnd := rand.New(rand.NewSource(2))
+    for i := 0; i < 5000; i++ {
+        key := IntKey(rnd.Intn(500))
+        if rnd.Intn(3) == 0 {
+            tree = tree.Delete(&key)
+            delete(expected, int(key))
+        } else {
+            tree = tree.Insert(&key, i)
+            expected[int(key)] = i
+        }
+        if i % 250 == 0 {
+            versions = append(versions, tree)
+            contents = append(contents, copyMap(expected))
+        }
+    }
+
+    checkContents(t, tree, expected)
+    for i, version := range versions {
+        checkContents(t, version, contents[i])
+    }
+
+    key := IntKey(1000)
+    if tree.Delete(&key) != tree {
+        t.Fatal("deleting a missing key returned a new tree")
+    }
+}
+
+func TestPersistentConcurrentModification(t *testing.T) {
+    base := NewPersistentRbTree()
+    expected := make(map[int]int)
+    for i := 0; i < 2000; i++ {
+        key := IntKey(i)
+        base = base.Insert(&key, i)
+        expected[i] = i
+    }
+
+    frozen := NewRbTree()
+    for i := 0; i < 2000; i++ {
+        frozen.Insert(newKey(IntKey(i)), i)
+    }
+    snapshot := frozen.Snapshot()
+
+    // the versions derived from the same trees must not write into their shared nodes
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(2)
+        go func(w int) {
+            defer wg.Done()
+            tree := base
+            rnd := rand.New(rand.NewSource(int64(w)))
+            for i := 0; i < 2000; i++ {
+                key := IntKey(rnd.Intn(4000))
+                if rnd.Intn(2) == 0 {
+                    tree = tree.Delete(&key)
+                } else {
+                    tree = tree.Insert(&key, i)
+                }
+            }
+            checkRbTree(t, tree.root)
+        }(w)
+        go func(w int) {
+            defer wg.Done()
+            tree := snapshot.ToRbTree()
+            rnd := rand.New(rand.NewSource(int64(w)))
+            for i := 0; i < 2000; i++ {
+                key := IntKey(rnd.Intn(4000))
+                if rnd.Intn(2) == 0 {
+                    tree.Delete(&key)
+                } else {
+                    tree.Insert(&key, i)
+                }
+            }
+            checkRbTree(t, tree.root)
+        }(w)
+    }
+    wg.Wait()
+
+    checkContents(t, base, expected)
+    checkContents(t, snapshot, expected)
+}
@@ -1,5 +1,10 @@
 package rbt
 
+import (
+    "math/bits"
+    "reflect"
+)
+
 // KeyComparison structure used as result of comparing two keys 
 type KeyComparison int8
 
@@ -34,12 +39,34 @@ type RbKey interface {
     ComparedTo(key RbKey) KeyComparison
 }
 
+// KeyComparator function used for ordering the keys of a tree instead of the ComparedTo method of the keys
+type KeyComparator func(a, b interface{}) KeyComparison
+
+// compareKeys compares the keys with the comparator if exists, otherwise with the ComparedTo method of the first key
+func compareKeys(compare KeyComparator, a, b RbKey) KeyComparison {
+    if compare != nil {
+        return compare(a, b)
+    }
+    return a.ComparedTo(b)
+}
+
+// rbAugmented interface is implemented by the node values keeping an aggregate of their subtree,
+// the aggregate is recalculated from the children wherever the size of the node is updated.
+// The values are changed in place, so the augmented trees are never frozen.
+type rbAugmented interface {
+    augment(node *rbNode)
+}
+
 // rbNode structure used for storing key and value pairs
 type rbNode struct {
     key RbKey
     value interface{}
     color byte
+    augmented bool
+    gen uint32
+    size int
     left, right *rbNode
+    parent *rbNode
 }
 
 // RbTree structure
@@ -47,8 +74,18 @@ type RbTree struct {
     root *rbNode
     count int
     version uint32
+    gen uint32
+    shared int
     onInsert InsertEvent
     onDelete DeleteEvent
+    valueCodec ValueCodec
+    compare KeyComparator
+    keyType reflect.Type
+    arena *rbArena
+    path []rbStep
+    splitPath []rbSplitStep
+    unlinked bool
+    augmented bool
 }
 
 // DeleteEvent function used on Insert or Delete operations
@@ -70,12 +107,29 @@ func NewRbTreeWithEvents(onInsert InsertEvent, onDelete DeleteEvent) *RbTree {
     }
 }
 
+// NewRbTreeWithComparator creates a new RbTree ordering its keys with the comparator and returns its address,
+// the ComparedTo method of the keys is never called. Returns nil if the comparator is nil.
+func NewRbTreeWithComparator(compare KeyComparator) *RbTree {
+    if compare == nil {
+        return nil
+    }
+    return &RbTree{
+        compare: compare,
+    }
+}
+
+// compareKeys compares the keys with the comparator of the tree
+func (tree *RbTree) compareKeys(a, b RbKey) KeyComparison {
+    return compareKeys(tree.compare, a, b)
+}
+
 // newRbNode creates a new rbNode and returns its address
 func newRbNode(key RbKey, value interface{}) *rbNode {
     result := &rbNode{
         key: key,
         value: value,
         color: red,
+        size: 1,
     }
     return result
 }
@@ -90,6 +144,22 @@ func isBlack(node *rbNode) bool {
     return node != nil && node.color == black 
 }
 
+// size returns the count of the nodes in the subtree rooted at node
+func size(node *rbNode) int {
+    if node == nil {
+        return 0
+    }
+    return node.size
+}
+
+// updateSize recalculates the count of the nodes and the aggregate of an augmented node in the subtree rooted at node
+func updateSize(node *rbNode) {
+    node.size = 1 + size(node.left) + size(node.right)
+    if node.augmented {
+        node.value.(rbAugmented).augment(node)
+    }
+}
+
 // min finds the smallest node key including the given node
 func min(node *rbNode) *rbNode {
     if node != nil {
@@ -111,43 +181,65 @@ func max(node *rbNode) *rbNode {
 }
 
 // floor returns the largest key node in the subtree rooted at x less than or equal to the given key
-func floor(node *rbNode, key RbKey) *rbNode {
-    if node == nil {
-        return nil
-    }
-    
-    switch key.ComparedTo(node.key) {
-    case KeysAreEqual:
-        return node
-    case KeyIsLess:
-        return floor(node.left, key)
-    default:
-        fn := floor(node.right, key)
-        if fn != nil {
-            return fn
+func floor(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    var result *rbNode
+    for node != nil {
+        switch compareKeys(compare, key, node.key) {
+        case KeysAreEqual:
+            return node
+        case KeyIsLess:
+            node = node.left
+        default:
+            result = node
+            node = node.right
         }
-        return node
     }
+    return result
 }
 
 // ceilig returns the smallest key node in the subtree rooted at x greater than or equal to the given key
-func ceiling(node *rbNode, key RbKey) *rbNode {  
-    if node == nil {
-        return nil
+func ceiling(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    var result *rbNode
+    for node != nil {
+        switch compareKeys(compare, key, node.key) {
+        case KeysAreEqual:
+            return node
+        case KeyIsGreater:
+            node = node.right
+        default:
+            result = node
+            node = node.left
+        }
     }
-    
-    switch key.ComparedTo(node.key) {
-    case KeysAreEqual:
-        return node
-    case KeyIsGreater:
-        return ceiling(node.right, key)
-    default:
-        cn := ceiling(node.left, key)
-        if cn != nil {
-            return cn
+    return result
+}
+
+// higher returns the smallest key node in the subtree rooted at x strictly greater than the given key
+func higher(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    var result *rbNode
+    for node != nil {
+        if compareKeys(compare, key, node.key) == KeyIsLess {
+            result = node
+            node = node.left
+        } else {
+            node = node.right
+        }
+    }
+    return result
+}
+
+// lower returns the largest key node in the subtree rooted at x strictly less than the given key
+func lower(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    var result *rbNode
+    for node != nil {
+        if compareKeys(compare, key, node.key) == KeyIsGreater {
+            result = node
+            node = node.right
+        } else {
+            node = node.left
         }
-        return node
     }
+    return result
 }
 
 // flipColor switchs the color of the node from red to black or black to red
@@ -159,85 +251,142 @@ func flipColor(node *rbNode) {
     }
 }
 
-// colorFlip switchs the color of the node and its children from red to black or black to red
-func colorFlip(node *rbNode) {
+// colorFlip owns the children of the owned node and switchs the color of the node and its children
+// from red to black or black to red
+func (tree *RbTree) colorFlip(node *rbNode) {
     flipColor(node)
-    flipColor(node.left)
-    flipColor(node.right)
+    flipColor(tree.ownLeft(node))
+    flipColor(tree.ownRight(node))
 }
 
-// rotateLeft makes a right-leaning link lean to the left
-func rotateLeft(node *rbNode) *rbNode {
-    child := node.right
+// rotateLeft makes a right-leaning link of the owned node lean to the left
+func (tree *RbTree) rotateLeft(node *rbNode) *rbNode {
+    child := tree.ownRight(node)
     node.right = child.left
+    tree.setParent(node.right, node)
     child.left = node
+    child.parent = node.parent
+    node.parent = child
     child.color = node.color
     node.color = red
+    child.size = node.size
+    updateSize(node)
+    if child.augmented {
+        child.value.(rbAugmented).augment(child)
+    }
 
     return child
 }
 
-// rotateRight makes a left-leaning link lean to the right
-func rotateRight(node *rbNode) *rbNode {
-    child := node.left
+// rotateRight makes a left-leaning link of the owned node lean to the right
+func (tree *RbTree) rotateRight(node *rbNode) *rbNode {
+    child := tree.ownLeft(node)
     node.left = child.right
+    tree.setParent(node.left, node)
     child.right = node
+    child.parent = node.parent
+    node.parent = child
     child.color = node.color
     node.color = red
+    child.size = node.size
+    updateSize(node)
+    if child.augmented {
+        child.value.(rbAugmented).augment(child)
+    }
 
     return child
 }
 
 // moveRedLeft makes node.left or one of its children red,
-// assuming that node is red and both children are black.
-func moveRedLeft(node *rbNode) *rbNode {
-    colorFlip(node)
+// assuming that the owned node is red and both children are black.
+func (tree *RbTree) moveRedLeft(node *rbNode) *rbNode {
+    tree.colorFlip(node)
     if isRed(node.right.left) {
-        node.right = rotateRight(node.right)
-        node = rotateLeft(node)
-        colorFlip(node)
+        node.right = tree.rotateRight(node.right)
+        node = tree.rotateLeft(node)
+        tree.colorFlip(node)
     }
     return node
 }
 
 // moveRedRight makes node.right or one of its children red,
-// assuming that node is red and both children are black.
-func moveRedRight(node *rbNode) *rbNode {
-    colorFlip(node)
+// assuming that the owned node is red and both children are black.
+func (tree *RbTree) moveRedRight(node *rbNode) *rbNode {
+    tree.colorFlip(node)
     if isRed(node.left.left) {
-        node = rotateRight(node)
-        colorFlip(node)
+        node = tree.rotateRight(node)
+        tree.colorFlip(node)
     }
     return node
 }
 
-// balance restores red-black tree invariant
-func balance(node *rbNode) *rbNode {
+// balance restores red-black tree invariant of the owned node
+func (tree *RbTree) balance(node *rbNode) *rbNode {
     if isRed(node.right) {
-        node = rotateLeft(node)
+        node = tree.rotateLeft(node)
     }
     if isRed(node.left) && isRed(node.left.left) {
-        node = rotateRight(node)
+        node = tree.rotateRight(node)
     }
     if isRed(node.left) && isRed(node.right) {
-        colorFlip(node)
+        tree.colorFlip(node)
     }
+    updateSize(node)
     return node
 }
 
-// deleteMin removes the smallest key and associated value from the tree
-func deleteMin(node *rbNode) *rbNode {
-    if node.left == nil {
-        return nil
-    }    
-    if isBlack(node.left) && !isRed(node.left.left) {
-        node = moveRedLeft(node)
+// own returns the node if it is owned by the tree, otherwise returns a copy of it owned by the tree.
+// The nodes of a tree are owned while their generation is the generation of the tree, the nodes of
+// the other generations are shared with other trees and copied before being changed.
+func (tree *RbTree) own(node *rbNode) *rbNode {
+    if node == nil || node.gen == tree.gen {
+        return node
+    }
+    return tree.copyNode(node)
+}
+
+// ownLeft replaces the shared left child of the owned node with its copy and returns the left child
+func (tree *RbTree) ownLeft(node *rbNode) *rbNode {
+    child := node.left
+    if child != nil && child.gen != tree.gen {
+        child = tree.copyNode(child)
+        child.parent = node
+        node.left = child
+    }
+    return child
+}
+
+// ownRight replaces the shared right child of the owned node with its copy and returns the right child
+func (tree *RbTree) ownRight(node *rbNode) *rbNode {
+    child := node.right
+    if child != nil && child.gen != tree.gen {
+        child = tree.copyNode(child)
+        child.parent = node
+        node.right = child
+    }
+    return child
+}
+
+// copyNode returns a copy of the shared node owned by the tree, allocated from the arena of the tree if exists
+func (tree *RbTree) copyNode(node *rbNode) *rbNode {
+    var result *rbNode
+    if tree.arena != nil {
+        result = tree.arena.alloc(nil, nil)
+    } else {
+        result = &rbNode{}
+    }
+    *result = *node
+    result.gen = tree.gen
+    tree.shared--
+    return result
+}
+
+// setParent sets the parent link of the child if it is owned by the tree,
+// the links of the shared nodes are never written as other trees may read them concurrently
+func (tree *RbTree) setParent(child *rbNode, parent *rbNode) {
+    if child != nil && child.gen == tree.gen {
+        child.parent = parent
     }
-    node.left = deleteMin(node.left)
-    /* if node.left != nil {
-        node.left.parent = node
-    } */
-    return balance(node)
 }
 
 // Count returns if count of the nodes stored.
@@ -271,7 +420,7 @@ func (tree *RbTree) Max() (RbKey, interface{}) {
 // Floor returns the largest key in the tree less than or equal to key
 func (tree *RbTree) Floor(key RbKey) (RbKey, interface{}) {
     if key != nil && tree.root != nil {
-        node := floor(tree.root, key)
+        node := floor(tree.root, key, tree.compare)
         if node == nil {
             return nil, nil
         }
@@ -283,7 +432,7 @@ func (tree *RbTree) Floor(key RbKey) (RbKey, interface{}) {
 // Ceiling returns the smallest key in the tree greater than or equal to key
 func (tree *RbTree) Ceiling(key RbKey) (RbKey, interface{}) {
     if key != nil && tree.root != nil {
-        node := ceiling(tree.root, key)
+        node := ceiling(tree.root, key, tree.compare)
         if node == nil {
             return nil, nil
         }
@@ -306,8 +455,13 @@ func (tree *RbTree) Get(key RbKey) (interface{}, bool) {
 
 // find returns the node if key found, otherwise returns nil 
 func (tree *RbTree) find(key RbKey) *rbNode {
-    for node := tree.root; node != nil; { 
-        switch key.ComparedTo(node.key) {
+    return lookup(tree.root, key, tree.compare)
+}
+
+// lookup returns the node in the subtree rooted at node if key found, otherwise returns nil 
+func lookup(node *rbNode, key RbKey, compare KeyComparator) *rbNode {
+    for node != nil { 
+        switch compareKeys(compare, key, node.key) {
         case KeyIsLess:
             node = node.left
         case KeyIsGreater:
@@ -328,34 +482,78 @@ func (tree *RbTree) Exists(key RbKey) bool {
 func (tree *RbTree) Insert(key RbKey, value interface{}) {
     if key != nil {
         tree.version++
-        tree.root = tree.insertNode(tree.root, key, value);
+        tree.root = tree.insertNode(tree.root, key, value)
+        tree.root.parent = nil
         tree.root.color = black
-        // tree.root.parent = nil
     }
 }
 
-// insertNode adds the given key and value into the node
+// insertNode adds the given key and value into the subtree rooted at node without recursion,
+// keeping the path in an explicit stack to rebalance the nodes on the way back to the root.
+// The shared nodes on the path are copied, so the tree never changes the nodes of other trees.
 func (tree *RbTree) insertNode(node *rbNode, key RbKey, value interface{}) *rbNode {
-    if node == nil {
-        tree.count++
-        return newRbNode(key, value)
-    }
+    path := tree.newPath()
+    node = tree.own(node)
+
+    var root *rbNode
+    for node != nil {
+        switch tree.compareKeys(key, node.key) {
+        case KeyIsLess:
+            path = append(path, rbStep{node: node, left: true})
+            node = tree.ownLeft(node)
+            continue
+        case KeyIsGreater:
+            path = append(path, rbStep{node: node})
+            node = tree.ownRight(node)
+            continue
+        }
 
-    switch key.ComparedTo(node.key) {
-    case KeyIsLess:
-        node.left  = tree.insertNode(node.left,  key, value)
-        // node.left.parent = node
-    case KeyIsGreater:
-        node.right = tree.insertNode(node.right, key, value)
-        // node.right.parent = node
-    default:
         if tree.onInsert == nil {
             node.value = value
         } else {
             node.value = tree.onInsert(key, node.value, value)
         }
+        root = tree.rebalanceInsert(path, tree.balance(node), false)
+        tree.releasePath(path)
+        return root
+    }
+
+    tree.count++
+    root = tree.rebalanceInsert(path, tree.newNode(key, value), true)
+    tree.releasePath(path)
+    return root
+}
+
+// rebalanceInsert links the child to the last node of the path and rebalances the nodes of the path
+// back to the root after an insert, returns the new root. Once a node and its child on the path keep
+// their places and colors, the nodes above them are as balanced as before the insert, so only their
+// sizes are updated for the new node. The augmented nodes are always rebalanced to the root.
+func (tree *RbTree) rebalanceInsert(path []rbStep, child *rbNode, added bool) *rbNode {
+    changed := added
+    for i := len(path) - 1; i >= 0; i-- {
+        node := path[i].node
+        if changed {
+            if path[i].left {
+                node.left = child
+            } else {
+                node.right = child
+            }
+            tree.setParent(child, node)
+        }
+
+        color := node.color
+        child = tree.balance(node)
+        if !changed && child == node && child.color == color && !tree.augmented {
+            if added {
+                for i--; i >= 0; i-- {
+                    path[i].node.size++
+                }
+            }
+            return path[0].node
+        }
+        changed = child != node || child.color != color
     }
-    return balance(node)
+    return child
 }
 
 // Delete deletes the given key from the tree
@@ -363,56 +561,113 @@ func (tree *RbTree) Delete(key RbKey) {
     tree.version++
     tree.root = tree.deleteNode(tree.root, key)
     if tree.root != nil {
+        tree.root.parent = nil
         tree.root.color = black
     }
 }
 
-// deleteNode deletes the given key from the node
+// deleteNode deletes the given key from the subtree rooted at node without recursion,
+// keeping the path in an explicit stack to rebalance the nodes on the way back to the root.
+// The shared nodes on the path are copied, so the tree never changes the nodes of other trees.
 func (tree *RbTree) deleteNode(node *rbNode, key RbKey) *rbNode {
-    if node == nil {
-        return nil
-    }
-    
-    cmp := key.ComparedTo(node.key)
-    if cmp == KeyIsLess {
-        if isBlack(node.left) && !isRed(node.left.left) {
-            node = moveRedLeft(node)
+    path := tree.newPath()
+    node = tree.own(node)
+
+    // child is the subtree replacing the last node of the path
+    var child *rbNode
+    for node != nil {
+        cmp := tree.compareKeys(key, node.key)
+        if cmp == KeyIsLess {
+            if isBlack(node.left) && !isRed(node.left.left) {
+                node = tree.moveRedLeft(node)
+            }
+            path = append(path, rbStep{node: node, left: true})
+            node = tree.ownLeft(node)
+            continue
         }
-        node.left = tree.deleteNode(node.left, key)
-    } else {
+
         if cmp == KeysAreEqual && tree.onDelete != nil {
             value := tree.onDelete(key, node.value)
             if value != nil {
                 node.value = value
-                return node
+                child = node
+                break
             }
         }
-        
+
         if isRed(node.left) {
-            node = rotateRight(node)
+            node = tree.rotateRight(node)
         }
-        
+
         if isBlack(node.right) && !isRed(node.right.left) {
-            node = moveRedRight(node)
+            node = tree.moveRedRight(node)
         }
-        
-        if key.ComparedTo(node.key) != KeysAreEqual {
-            node.right = tree.deleteNode(node.right, key)
-        } else {
-            if node.right == nil {
-                return nil
+
+        if tree.compareKeys(key, node.key) != KeysAreEqual {
+            path = append(path, rbStep{node: node})
+            node = tree.ownRight(node)
+            continue
+        }
+
+        if node.right != nil {
+            // the node takes the smallest key of its right subtree, which node is removed instead
+            target := node
+            path = append(path, rbStep{node: node})
+            node = tree.ownRight(node)
+            for node.left != nil {
+                if isBlack(node.left) && !isRed(node.left.left) {
+                    node = tree.moveRedLeft(node)
+                }
+                path = append(path, rbStep{node: node, left: true})
+                node = tree.ownLeft(node)
             }
+            target.key = node.key
+            target.value = node.value
+        }
+
+        tree.count--
+        tree.releaseNode(node)
+        break
+    }
+
+    root := tree.rebalance(path, child)
+    tree.releasePath(path)
+    return root
+}
 
-            rm := min(node.right)
-            node.key   = rm.key
-            node.value = rm.value
-            node.right = deleteMin(node.right)
+// rbStep structure is a node on the path from the root with the side taken to its child
+type rbStep struct {
+    node *rbNode
+    left bool
+}
+
+// newPath returns the empty path of the tree having room for the height of the tree,
+// the path is kept in the tree between the modifications
+func (tree *RbTree) newPath() []rbStep {
+    if height := 2 * bits.Len(uint(tree.count + 1)) + 1; cap(tree.path) < height {
+        tree.path = make([]rbStep, 0, height)
+    }
+    return tree.path[:0]
+}
 
-            rm.left = nil
-            rm.right = nil
-            
-            tree.count--
+// releasePath keeps the path for the next modification dropping its nodes
+func (tree *RbTree) releasePath(path []rbStep) {
+    clear(path)
+    tree.path = path[:0]
+}
+
+// rebalance links the child to the last node of the path and rebalances the nodes of the path
+// back to the root, returns the new root
+func (tree *RbTree) rebalance(path []rbStep, child *rbNode) *rbNode {
+    for i := len(path) - 1; i >= 0; i-- {
+        node := path[i].node
+        if path[i].left {
+            node.left = child
+        } else {
+            node.right = child
         }
+        tree.setParent(child, node)
+        child = tree.balance(node)
     }
-    return balance(node)
+    return child
 }
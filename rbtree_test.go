@@ -2,6 +2,7 @@ package rbt
 
 import (
     "fmt"
+    "math/rand"
     "runtime"
 	"testing"
     "time"
@@ -110,4 +111,228 @@ func TestInsertDeleteAndGetMap(t *testing.T) {
     } else {
         fmt.Printf("Mem map allocated: %3.3f MB\n", float64(mem2.Alloc - mem1.Alloc)/(1024*1024))
     }
-}
\ No newline at end of file
+}
+
+// checkRbTree validates the left-leaning red-black invariants and the subtree sizes
+// of the subtree and returns its black height
+func checkRbTree(t *testing.T, node *rbNode) int {
+    if node == nil {
+        return 0
+    }
+    if isRed(node.right) {
+        t.Fatalf("right-leaning red link at %v", node.key)
+    }
+    if isRed(node) && isRed(node.left) {
+        t.Fatalf("two red links in a row at %v", node.key)
+    }
+    if node.left != nil && node.left.key.ComparedTo(node.key) != KeyIsLess {
+        t.Fatalf("left key %v is not less than %v", node.left.key, node.key)
+    }
+    if node.right != nil && node.right.key.ComparedTo(node.key) != KeyIsGreater {
+        t.Fatalf("right key %v is not greater than %v", node.right.key, node.key)
+    }
+    if node.size != 1 + size(node.left) + size(node.right) {
+        t.Fatalf("size of %v is %d, want %d", node.key, node.size, 1 + size(node.left) + size(node.right))
+    }
+
+    lh, rh := checkRbTree(t, node.left), checkRbTree(t, node.right)
+    if lh != rh {
+        t.Fatalf("black height mismatch at %v: %d != %d", node.key, lh, rh)
+    }
+    if isBlack(node) {
+        lh++
+    }
+    return lh
+}
+
+func TestRankAndSelect(t *testing.T) {
+    tree := NewRbTree()
+    expected := make(map[int]bool)
+
+    rnd := rand.New(rand.NewSource(1))
+    for i := 0; i < 20000; i++ {
+        key := IntKey(rnd.Intn(2000))
+        if rnd.Intn(3) == 0 {
+            tree.Delete(&key)
+            delete(expected, int(key))
+        } else {
+            tree.Insert(&key, int(key))
+            expected[int(key)] = true
+        }
+    }
+    checkRbTree(t, tree.root)
+
+    if tree.Count() != len(expected) || size(tree.root) != len(expected) {
+        t.Fatalf("Count() = %d, size = %d, want %d", tree.Count(), size(tree.root), len(expected))
+    }
+
+    sorted := make([]int, 0, len(expected))
+    for i := 0; i < 2000; i++ {
+        if expected[i] {
+            sorted = append(sorted, i)
+        }
+    }
+
+    for i, k := range sorted {
+        key, value := tree.Select(i)
+        if key == nil || int(*key.(*IntKey)) != k || value != k {
+            t.Fatalf("Select(%d) = %v, %v, want %d", i, key, value, k)
+        }
+        if rank := tree.Rank(key); rank != i {
+            t.Fatalf("Rank(%d) = %d, want %d", k, rank, i)
+        }
+    }
+    if key, _ := tree.Select(len(sorted)); key != nil {
+        t.Fatalf("Select(%d) = %v, want nil", len(sorted), key)
+    }
+    if key, _ := tree.Select(-1); key != nil {
+        t.Fatalf("Select(-1) = %v, want nil", key)
+    }
+
+    for i := 0; i < 1000; i++ {
+        lo, hi := rnd.Intn(2100) - 50, rnd.Intn(2100) - 50
+        want := 0
+        for _, k := range sorted {
+            if (k >= lo && k <= hi) || (k >= hi && k <= lo) {
+                want++
+            }
+        }
+        loKey, hiKey := IntKey(lo), IntKey(hi)
+        if count := tree.CountBetween(&loKey, &hiKey); count != want {
+            t.Fatalf("CountBetween(%d, %d) = %d, want %d", lo, hi, count, want)
+        }
+    }
+}
+
+
+func TestBuildFromSorted(t *testing.T) {
+    fmt.Println("\nTestBuildFromSorted\n~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~")
+
+    for count := 0; count <= 300; count++ {
+        keys := make([]RbKey, count)
+        values := make([]interface{}, count)
+        for i := range keys {
+            key := IntKey(i * 2)
+            keys[i], values[i] = &key, i
+        }
+
+        tree, err := NewRbTreeFromSorted(keys, values)
+        if err != nil {
+            t.Fatal(err)
+        }
+        checkRbTree(t, tree.root)
+        if isRed(tree.root) || tree.Count() != count || size(tree.root) != count {
+            t.Fatalf("built %d keys, Count() = %d", count, tree.Count())
+        }
+        for i := 0; i < count; i++ {
+            if value, ok := tree.Get(keys[i]); !ok || value != i {
+                t.Fatalf("Get(%d) = %v, %v", i * 2, value, ok)
+            }
+        }
+
+        key := IntKey(count)
+        tree.Insert(&key, nil)
+        tree.Delete(&key)
+        checkRbTree(t, tree.root)
+    }
+
+    keys := make([]RbKey, 1000000)
+    for i := range keys {
+        key := IntKey(i)
+        keys[i] = &key
+    }
+
+    t1 := time.Now()
+    tree, err := NewRbTreeFromSorted(keys, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    fmt.Printf("Build time: %.5f sec with count %d\n", float64(time.Now().Sub(t1).Nanoseconds())/float64(time.Second.Nanoseconds()), tree.Count())
+    checkRbTree(t, tree.root)
+
+    one, two := IntKey(1), IntKey(2)
+    if _, err := NewRbTreeFromSorted([]RbKey{&two, &one}, nil); err != ErrKeysNotSorted {
+        t.Fatalf("unsorted keys returned %v, want %v", err, ErrKeysNotSorted)
+    }
+    if _, err := NewRbTreeFromSorted([]RbKey{&one, &one}, nil); err != ErrDuplicateKey {
+        t.Fatalf("duplicate keys returned %v, want %v", err, ErrDuplicateKey)
+    }
+    if _, err := NewRbTreeFromSorted([]RbKey{&one}, []interface{}{1, 2}); err != ErrArgumentLengthMismatch {
+        t.Fatalf("mismatched values returned %v, want %v", err, ErrArgumentLengthMismatch)
+    }
+
+    merged := NewRbTreeWithEvents(func(key RbKey, oldValue interface{}, newValue interface{}) interface{} {
+        return oldValue.(int) + newValue.(int)
+    }, nil)
+    err = merged.BuildFromSorted(func(yield func(RbKey, interface{}) bool) {
+        _ = yield(&one, 1) && yield(&one, 2) && yield(&two, 3)
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if value, _ := merged.Get(&one); merged.Count() != 2 || value != 3 {
+        t.Fatalf("merged duplicates to %v with count %d", value, merged.Count())
+    }
+}
+
+func TestRbTreeWithComparator(t *testing.T) {
+    if NewRbTreeWithComparator(nil) != nil {
+        t.Fatalf("NewRbTreeWithComparator(nil) returned a tree")
+    }
+
+    tree := NewRbTreeWithComparator(func(a, b interface{}) KeyComparison {
+        switch x, y := *a.(*IntKey), *b.(*IntKey); {
+        case x > y:
+            return KeyIsLess
+        case x < y:
+            return KeyIsGreater
+        default:
+            return KeysAreEqual
+        }
+    })
+    for i := 0; i < 100; i++ {
+        tree.Insert(newKey(IntKey(i)), i)
+    }
+    for i := 0; i < 100; i += 3 {
+        tree.Delete(newKey(IntKey(i)))
+    }
+
+    previous := 100
+    for key := range tree.All() {
+        k := int(*key.(*IntKey))
+        if k >= previous || k % 3 == 0 {
+            t.Fatalf("All yielded %d after %d", k, previous)
+        }
+        previous = k
+    }
+
+    if key, _ := tree.Min(); *key.(*IntKey) != 98 {
+        t.Fatalf("Min() = %v, want 98", *key.(*IntKey))
+    }
+    if key, _ := tree.Floor(newKey(IntKey(51))); *key.(*IntKey) != 52 {
+        t.Fatalf("Floor(51) = %v, want 52", *key.(*IntKey))
+    }
+    if key, _ := tree.Ceiling(newKey(IntKey(51))); *key.(*IntKey) != 50 {
+        t.Fatalf("Ceiling(51) = %v, want 50", *key.(*IntKey))
+    }
+    if rank := tree.Rank(newKey(IntKey(97))); rank != 1 {
+        t.Fatalf("Rank(97) = %d, want 1", rank)
+    }
+
+    count := 0
+    iterator, _ := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {
+        count++
+    })
+    if n, err := iterator.Between(newKey(IntKey(10)), newKey(IntKey(20))); err != nil || n != 8 || count != 8 {
+        t.Fatalf("Between(10, 20) = %d, %v, want 8", n, err)
+    }
+
+    left, right := tree.Split(newKey(IntKey(50)))
+    if key, _ := left.Max(); *key.(*IntKey) != 52 {
+        t.Fatalf("Split left Max() = %v, want 52", *key.(*IntKey))
+    }
+    joined, err := Join(left, right)
+    if err != nil || joined.Count() != 66 {
+        t.Fatalf("Join returned %v with count %d", err, joined.Count())
+    }
+}
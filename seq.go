@@ -0,0 +1,99 @@
+package rbt
+
+import (
+    "iter"
+)
+
+// rbBound structure used for limiting the range of a sequence walk
+type rbBound struct {
+    key RbKey
+    inclusive bool
+}
+
+// rbSeqWalk structure holds the state of a range-over-func walk on a RbTree
+type rbSeqWalk struct {
+    rbWalker
+    tree *RbTree
+    version uint32
+    yield func(RbKey, interface{}) bool
+}
+
+// All returns a sequence of all items of the RbTree in ascending order
+func (tree *RbTree) All() iter.Seq2[RbKey, interface{}] {
+    return tree.seq(nil, nil, false)
+}
+
+// Backward returns a sequence of all items of the RbTree in descending order
+func (tree *RbTree) Backward() iter.Seq2[RbKey, interface{}] {
+    return tree.seq(nil, nil, true)
+}
+
+// Range returns a sequence of the items of the RbTree in ascending order that the key of the item
+// is greater or equal to loKey and less or equal to hiKey, a nil key leaves that side of the range open
+func (tree *RbTree) Range(loKey RbKey, hiKey RbKey) iter.Seq2[RbKey, interface{}] {
+    if loKey != nil && hiKey != nil && tree.compareKeys(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return tree.seq(inclusiveBound(loKey), inclusiveBound(hiKey), false)
+}
+
+// From returns a sequence of the items of the RbTree in ascending order that the key of the item
+// is greater or equal to the given key
+func (tree *RbTree) From(key RbKey) iter.Seq2[RbKey, interface{}] {
+    return tree.seq(inclusiveBound(key), nil, false)
+}
+
+// Until returns a sequence of the items of the RbTree in ascending order that the key of the item
+// is less than the given key
+func (tree *RbTree) Until(key RbKey) iter.Seq2[RbKey, interface{}] {
+    var hi *rbBound
+    if key != nil {
+        hi = &rbBound{key: key}
+    }
+    return tree.seq(nil, hi, false)
+}
+
+// inclusiveBound creates an inclusive bound for the key, nil if the key is nil
+func inclusiveBound(key RbKey) *rbBound {
+    if key == nil {
+        return nil
+    }
+    return &rbBound{key: key, inclusive: true}
+}
+
+// seq creates the sequence walking between the given bounds.
+// The sequence panics with ErrEnumeratorModified if the tree gets modified while iterating.
+func (tree *RbTree) seq(lo, hi *rbBound, descending bool) iter.Seq2[RbKey, interface{}] {
+    return func(yield func(RbKey, interface{}) bool) {
+        walk := &rbSeqWalk{
+            rbWalker: rbWalker{lo: lo, hi: hi, descending: descending, compare: tree.compare},
+            tree: tree,
+            version: tree.version,
+            yield: yield,
+        }
+        walk.walk(tree.root)
+    }
+}
+
+// walk yields in order the items of the subtree rooted at node inside the bounds, returns 'false' if the walk stopped
+func (walk *rbSeqWalk) walk(node *rbNode) bool {
+    for node != nil {
+        first, last, inFirst, inLast := walk.children(node)
+        if inFirst {
+            if first != nil && !walk.walk(first) {
+                return false
+            }
+            if !inLast {
+                return false
+            }
+            if walk.version != walk.tree.version {
+                panic(ErrEnumeratorModified)
+            }
+            if !walk.yield(node.key, node.value) {
+                return false
+            }
+        }
+        node = last
+    }
+    return true
+}
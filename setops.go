@@ -0,0 +1,143 @@
+package rbt
+
+// MergeEvent function used on set operations to resolve the value of a key existing in both trees
+type MergeEvent func(key RbKey, value interface{}, otherValue interface{}) (mergedValue interface{})
+
+// newSetTree creates the tree holding the result of a set operation on the tree with a new generation
+func (tree *RbTree) newSetTree() *RbTree {
+    result := tree.newPart(nextGeneration())
+    result.version = 0
+    return result
+}
+
+// setResult makes the subtree the items of the result of the set operation.
+// The result shares the unchanged nodes with the source trees, so the source trees get frozen.
+func (tree *RbTree) setResult(source *RbTree, other *RbTree, root *rbNode) *RbTree {
+    source.freeze()
+    other.freeze()
+    tree.setPart(root)
+    return tree
+}
+
+// Union returns a new tree containing the keys existing in any of the trees.
+// The values of the keys existing in both trees are resolved with merge,
+// if merge is nil the value of the other tree is used.
+// The operation runs in O(m log(n/m + 1)) time and keeps the items of the trees, but freezes them
+// as Snapshot does since they share their nodes with the result.
+func (tree *RbTree) Union(other *RbTree, merge MergeEvent) *RbTree {
+    result := tree.newSetTree()
+    root, _ := result.union(tree.root, blackHeight(tree.root), other.root, blackHeight(other.root), merge)
+    return result.setResult(tree, other, root)
+}
+
+// Intersect returns a new tree containing the keys existing in both trees.
+// The values are resolved with merge, if merge is nil the value of the tree is used.
+// The operation runs in O(m log(n/m + 1)) time and keeps the items of the trees, but freezes them
+// as Snapshot does since they share their nodes with the result.
+func (tree *RbTree) Intersect(other *RbTree, merge MergeEvent) *RbTree {
+    result := tree.newSetTree()
+    root, _ := result.intersect(tree.root, blackHeight(tree.root), other.root, blackHeight(other.root), merge)
+    return result.setResult(tree, other, root)
+}
+
+// Difference returns a new tree containing the keys of the tree not existing in the other tree.
+// The operation runs in O(m log(n/m + 1)) time and keeps the items of the trees, but freezes them
+// as Snapshot does since they share their nodes with the result.
+func (tree *RbTree) Difference(other *RbTree) *RbTree {
+    result := tree.newSetTree()
+    root, _ := result.difference(tree.root, blackHeight(tree.root), other.root, blackHeight(other.root))
+    return result.setResult(tree, other, root)
+}
+
+// SymmetricDifference returns a new tree containing the keys existing in only one of the trees.
+// The operation runs in O(m log(n/m + 1)) time and keeps the items of the trees, but freezes them
+// as Snapshot does since they share their nodes with the result.
+func (tree *RbTree) SymmetricDifference(other *RbTree) *RbTree {
+    result := tree.newSetTree()
+    root, _ := result.symmetricDifference(tree.root, blackHeight(tree.root), other.root, blackHeight(other.root))
+    return result.setResult(tree, other, root)
+}
+
+// union returns the union of the subtrees with its black height
+func (tree *RbTree) union(node *rbNode, height int, other *rbNode, otherHeight int, merge MergeEvent) (*rbNode, int) {
+    if node == nil {
+        return other, otherHeight
+    }
+    if other == nil {
+        return node, height
+    }
+
+    nodeHeight := childHeight(node, height)
+    otherLeft, otherLeftHeight, found, otherRight, otherRightHeight := tree.split(other, otherHeight, node.key)
+
+    left, leftHeight := tree.union(node.left, nodeHeight, otherLeft, otherLeftHeight, merge)
+    right, rightHeight := tree.union(node.right, nodeHeight, otherRight, otherRightHeight, merge)
+
+    middle := tree.own(node)
+    if found != nil {
+        if merge == nil {
+            middle.value = found.value
+        } else {
+            middle.value = merge(node.key, node.value, found.value)
+        }
+    }
+    return tree.join(left, leftHeight, middle, right, rightHeight)
+}
+
+// intersect returns the intersection of the subtrees with its black height
+func (tree *RbTree) intersect(node *rbNode, height int, other *rbNode, otherHeight int, merge MergeEvent) (*rbNode, int) {
+    if node == nil || other == nil {
+        return nil, 0
+    }
+
+    nodeHeight := childHeight(node, height)
+    otherLeft, otherLeftHeight, found, otherRight, otherRightHeight := tree.split(other, otherHeight, node.key)
+
+    left, leftHeight := tree.intersect(node.left, nodeHeight, otherLeft, otherLeftHeight, merge)
+    right, rightHeight := tree.intersect(node.right, nodeHeight, otherRight, otherRightHeight, merge)
+
+    if found == nil {
+        return tree.join2(left, leftHeight, right, rightHeight)
+    }
+
+    middle := tree.own(node)
+    if merge != nil {
+        middle.value = merge(node.key, node.value, found.value)
+    }
+    return tree.join(left, leftHeight, middle, right, rightHeight)
+}
+
+// difference returns the keys of the subtree not existing in the other subtree with its black height
+func (tree *RbTree) difference(node *rbNode, height int, other *rbNode, otherHeight int) (*rbNode, int) {
+    if node == nil || other == nil {
+        return node, height
+    }
+
+    otherChildHeight := childHeight(other, otherHeight)
+    nodeLeft, nodeLeftHeight, _, nodeRight, nodeRightHeight := tree.split(node, height, other.key)
+
+    left, leftHeight := tree.difference(nodeLeft, nodeLeftHeight, other.left, otherChildHeight)
+    right, rightHeight := tree.difference(nodeRight, nodeRightHeight, other.right, otherChildHeight)
+    return tree.join2(left, leftHeight, right, rightHeight)
+}
+
+// symmetricDifference returns the keys existing in only one of the subtrees with its black height
+func (tree *RbTree) symmetricDifference(node *rbNode, height int, other *rbNode, otherHeight int) (*rbNode, int) {
+    if node == nil {
+        return other, otherHeight
+    }
+    if other == nil {
+        return node, height
+    }
+
+    nodeHeight := childHeight(node, height)
+    otherLeft, otherLeftHeight, found, otherRight, otherRightHeight := tree.split(other, otherHeight, node.key)
+
+    left, leftHeight := tree.symmetricDifference(node.left, nodeHeight, otherLeft, otherLeftHeight)
+    right, rightHeight := tree.symmetricDifference(node.right, nodeHeight, otherRight, otherRightHeight)
+
+    if found != nil {
+        return tree.join2(left, leftHeight, right, rightHeight)
+    }
+    return tree.join(left, leftHeight, tree.own(node), right, rightHeight)
+}
@@ -0,0 +1,162 @@
+package rbt
+
+import (
+    "math/rand"
+    "testing"
+)
+
+// randomTree creates a tree with count random keys less than limit and returns it with its items
+func randomTree(rnd *rand.Rand, count int, limit int) (*RbTree, map[int]int) {
+    tree := NewRbTree()
+    items := make(map[int]int)
+    for i := 0; i < count; i++ {
+        k := rnd.Intn(limit)
+        key := IntKey(k)
+        tree.Insert(&key, k * 10 + 1)
+        items[k] = k * 10 + 1
+    }
+    return tree, items
+}
+
+// checkItems validates that the tree stores exactly the expected items
+func checkItems(t *testing.T, name string, tree *RbTree, expected map[int]int) {
+    checkRbTree(t, tree.root)
+    if isRed(tree.root) {
+        t.Fatalf("%s: red root", name)
+    }
+    if tree.Count() != len(expected) || size(tree.root) != len(expected) {
+        t.Fatalf("%s: Count() = %d, size = %d, want %d", name, tree.Count(), size(tree.root), len(expected))
+    }
+    for k, v := range expected {
+        key := IntKey(k)
+        if value, ok := tree.Get(&key); !ok || value != v {
+            t.Fatalf("%s: Get(%d) = %v, %v, want %d", name, k, value, ok, v)
+        }
+    }
+}
+
+func TestSetOperations(t *testing.T) {
+    rnd := rand.New(rand.NewSource(1))
+    sum := func(key RbKey, value interface{}, otherValue interface{}) interface{} {
+        return value.(int) + otherValue.(int)
+    }
+
+    sizes := [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1}, {10, 1000}, {1000, 10}, {500, 500}, {3000, 2000}}
+    for _, counts := range sizes {
+        a, aItems := randomTree(rnd, counts[0], 4000)
+        b, bItems := randomTree(rnd, counts[1], 4000)
+
+        union := make(map[int]int)
+        intersection := make(map[int]int)
+        difference := make(map[int]int)
+        symmetric := make(map[int]int)
+        for k, v := range aItems {
+            if w, ok := bItems[k]; ok {
+                union[k] = v + w
+                intersection[k] = v + w
+            } else {
+                union[k] = v
+                difference[k] = v
+                symmetric[k] = v
+            }
+        }
+        for k, w := range bItems {
+            if _, ok := aItems[k]; !ok {
+                union[k] = w
+                symmetric[k] = w
+            }
+        }
+
+        results := []*RbTree{
+            a.Union(b, sum),
+            a.Intersect(b, sum),
+            a.Difference(b),
+            a.SymmetricDifference(b),
+        }
+        expected := []map[int]int{union, intersection, difference, symmetric}
+        names := []string{"Union", "Intersect", "Difference", "SymmetricDifference"}
+
+        for i, result := range results {
+            checkItems(t, names[i], result, expected[i])
+        }
+        checkItems(t, "source", a, aItems)
+        checkItems(t, "other", b, bItems)
+
+        for k := range aItems {
+            key := IntKey(k)
+            a.Delete(&key)
+        }
+        for i := 0; i < 100; i++ {
+            key := IntKey(rnd.Intn(4000))
+            b.Insert(&key, -1)
+        }
+        for i, result := range results {
+            checkItems(t, names[i], result, expected[i])
+        }
+
+        for k := range union {
+            key := IntKey(k)
+            results[0].Delete(&key)
+        }
+        if !results[0].IsEmpty() {
+            t.Fatalf("Union result not empty after deleting all keys")
+        }
+        checkItems(t, "Intersect", results[1], intersection)
+    }
+}
+
+
+func TestSplitAndJoin(t *testing.T) {
+    rnd := rand.New(rand.NewSource(2))
+
+    for _, count := range []int{0, 1, 2, 10, 1000} {
+        for _, at := range []int{-1, 0, 500, 1999, 5000} {
+            tree, items := randomTree(rnd, count, 2000)
+            key := IntKey(at)
+
+            version := tree.version
+            left, right := tree.Split(&key)
+            if !tree.IsEmpty() || tree.Count() != 0 || tree.version == version {
+                t.Fatalf("tree not emptied by Split")
+            }
+
+            below, above := make(map[int]int), make(map[int]int)
+            for k, v := range items {
+                if k < at {
+                    below[k] = v
+                } else {
+                    above[k] = v
+                }
+            }
+            checkItems(t, "left", left, below)
+            checkItems(t, "right", right, above)
+
+            joined, err := Join(left, right)
+            if err != nil {
+                t.Fatal(err)
+            }
+            checkItems(t, "joined", joined, items)
+            if !left.IsEmpty() || !right.IsEmpty() {
+                t.Fatalf("trees not emptied by Join")
+            }
+        }
+    }
+
+    a, _ := randomTree(rnd, 100, 1000)
+    b, _ := randomTree(rnd, 100, 1000)
+    if _, err := Join(a, b); err != ErrKeyRangesOverlap {
+        t.Fatalf("Join of overlapping trees returned %v, want %v", err, ErrKeyRangesOverlap)
+    }
+
+    tree, items := randomTree(rnd, 1000, 2000)
+    snapshot := tree.Snapshot()
+    key := IntKey(1000)
+    left, right := tree.Split(&key)
+    joined, _ := Join(right, left)
+    if joined != nil {
+        t.Fatalf("Join of swapped halves succeeded")
+    }
+    joined, _ = Join(left, right)
+    checkItems(t, "joined", joined, items)
+    checkContents(t, snapshot, items)
+}
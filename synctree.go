@@ -0,0 +1,437 @@
+package rbt
+
+import (
+    "io"
+    "iter"
+    "sync"
+)
+
+// SyncRbTree structure is the concurrency-safe wrapper of RbTree.
+// All the read operations and iterations hold a read lock on the tree,
+// while the modifications hold a write lock, so the iteration callbacks
+// and range loops must not modify or re-enter the same SyncRbTree.
+type SyncRbTree struct {
+    lock sync.RWMutex
+    tree *RbTree
+}
+
+// syncRbIterator structure wraps a RbIterator holding a read lock on the
+// SyncRbTree for the duration of the iterator walks
+type syncRbIterator struct {
+    RbIterator
+    owner *SyncRbTree
+}
+
+// NewSyncRbTree creates a new SyncRbTree and returns its address
+func NewSyncRbTree() *SyncRbTree {
+    return &SyncRbTree{
+        tree: NewRbTree(),
+    }
+}
+
+// NewSyncRbTreeWithEvents creates a new SyncRbTree assigning its insert and delete events and returns its address
+func NewSyncRbTreeWithEvents(onInsert InsertEvent, onDelete DeleteEvent) *SyncRbTree {
+    return &SyncRbTree{
+        tree: NewRbTreeWithEvents(onInsert, onDelete),
+    }
+}
+
+// NewSyncRbTreeWithComparator creates a new SyncRbTree ordering its keys with the comparator and returns its address,
+// returns nil if the comparator is nil
+func NewSyncRbTreeWithComparator(compare KeyComparator) *SyncRbTree {
+    if compare == nil {
+        return nil
+    }
+    return &SyncRbTree{
+        tree: NewRbTreeWithComparator(compare),
+    }
+}
+
+// Count returns if count of the nodes stored.
+func (tree *SyncRbTree) Count() int {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Count()
+}
+
+// IsEmpty returns if the tree has any node.
+func (tree *SyncRbTree) IsEmpty() bool {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.IsEmpty()
+}
+
+// Min returns the smallest key in the tree.
+func (tree *SyncRbTree) Min() (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Min()
+}
+
+// Max returns the largest key in the tree.
+func (tree *SyncRbTree) Max() (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Max()
+}
+
+// Floor returns the largest key in the tree less than or equal to key
+func (tree *SyncRbTree) Floor(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Floor(key)
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to key
+func (tree *SyncRbTree) Ceiling(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Ceiling(key)
+}
+
+// Successor returns the smallest key in the tree strictly greater than key
+func (tree *SyncRbTree) Successor(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Successor(key)
+}
+
+// Predecessor returns the largest key in the tree strictly less than key
+func (tree *SyncRbTree) Predecessor(key RbKey) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Predecessor(key)
+}
+
+// Get returns the stored value if key found and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *SyncRbTree) Get(key RbKey) (interface{}, bool) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Get(key)
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *SyncRbTree) Exists(key RbKey) bool {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Exists(key)
+}
+
+// Rank returns the count of the keys in the tree strictly less than the given key
+func (tree *SyncRbTree) Rank(key RbKey) int {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Rank(key)
+}
+
+// Select returns the key and value at the given zero based position in the sorted order of the tree
+func (tree *SyncRbTree) Select(index int) (RbKey, interface{}) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.Select(index)
+}
+
+// CountBetween returns the count of the keys in the tree that are
+// greater or equal to loKey and less or equal to hiKey
+func (tree *SyncRbTree) CountBetween(loKey RbKey, hiKey RbKey) int {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.CountBetween(loKey, hiKey)
+}
+
+// Insert inserts the given key and value into the tree
+func (tree *SyncRbTree) Insert(key RbKey, value interface{}) {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    tree.tree.Insert(key, value)
+}
+
+// Delete deletes the given key from the tree
+func (tree *SyncRbTree) Delete(key RbKey) {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    tree.tree.Delete(key)
+}
+
+// TryInsert inserts the given key and value into the tree,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryInsert(key RbKey, value interface{}) error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.TryInsert(key, value)
+}
+
+// TryGet returns the stored value if key found and 'true',
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryGet(key RbKey) (interface{}, bool, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.TryGet(key)
+}
+
+// TryDelete deletes the given key from the tree,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryDelete(key RbKey) error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.TryDelete(key)
+}
+
+// TryFloor returns the largest key in the tree less than or equal to key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryFloor(key RbKey) (RbKey, interface{}, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.TryFloor(key)
+}
+
+// TryCeiling returns the smallest key in the tree greater than or equal to key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryCeiling(key RbKey) (RbKey, interface{}, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.TryCeiling(key)
+}
+
+// TryRank returns the count of the keys in the tree strictly less than the given key,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryRank(key RbKey) (int, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.TryRank(key)
+}
+
+// TryRange returns the sequence of Range,
+// returns ErrKeyTypeMismatch if the type of a bound key differs from the key type of the tree
+func (tree *SyncRbTree) TryRange(loKey RbKey, hiKey RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(loKey, hiKey); err != nil {
+        return nil, err
+    }
+    return tree.Range(loKey, hiKey), nil
+}
+
+// TryFrom returns the sequence of From,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryFrom(key RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(key, nil); err != nil {
+        return nil, err
+    }
+    return tree.From(key), nil
+}
+
+// TryUntil returns the sequence of Until,
+// returns ErrKeyTypeMismatch if the key type differs from the key type of the tree
+func (tree *SyncRbTree) TryUntil(key RbKey) (iter.Seq2[RbKey, interface{}], error) {
+    if err := tree.checkBoundKeyTypes(nil, key); err != nil {
+        return nil, err
+    }
+    return tree.Until(key), nil
+}
+
+// checkBoundKeyTypes checks the types of the bound keys holding a read lock on the tree
+func (tree *SyncRbTree) checkBoundKeyTypes(loKey RbKey, hiKey RbKey) error {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.checkBoundKeyTypes(loKey, hiKey)
+}
+
+// Snapshot returns a point-in-time view of the tree in O(1)
+func (tree *SyncRbTree) Snapshot() *PersistentRbTree {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.Snapshot()
+}
+
+// SetValueCodec sets the codec used for encoding and decoding the values of the tree
+func (tree *SyncRbTree) SetValueCodec(codec ValueCodec) {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    tree.tree.SetValueCodec(codec)
+}
+
+// WriteTo writes the items of the tree to the writer in the binary format holding a read lock on the tree
+func (tree *SyncRbTree) WriteTo(w io.Writer) (int64, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.WriteTo(w)
+}
+
+// ReadFrom replaces the items of the tree with the items read from the reader in the binary format
+func (tree *SyncRbTree) ReadFrom(r io.Reader) (int64, error) {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.ReadFrom(r)
+}
+
+// MarshalJSON encodes the items of the tree in ascending order holding a read lock on the tree
+func (tree *SyncRbTree) MarshalJSON() ([]byte, error) {
+    tree.lock.RLock()
+    defer tree.lock.RUnlock()
+    return tree.tree.MarshalJSON()
+}
+
+// UnmarshalJSON replaces the items of the tree with the items decoded from the JSON form
+func (tree *SyncRbTree) UnmarshalJSON(data []byte) error {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.tree.UnmarshalJSON(data)
+}
+
+// NewRbIterator creates a new iterator holding a read lock on the tree while walking
+func (tree *SyncRbTree) NewRbIterator(callback RbIterationCallback) (RbIterator, error) {
+    if tree == nil {
+        return nil, ArgumentNilError("tree")
+    }
+
+    // the callback gets the wrapper, so the callbacks never reach the unguarded iterator
+    result := &syncRbIterator{owner: tree}
+    var wrapped RbIterationCallback
+    if callback != nil {
+        wrapped = func(iterator RbIterator, key RbKey, value interface{}) {
+            callback(result, key, value)
+        }
+    }
+
+    tree.lock.RLock()
+    iterator, err := tree.tree.NewRbIterator(wrapped)
+    tree.lock.RUnlock()
+    if err != nil {
+        return nil, err
+    }
+    result.RbIterator = iterator
+    return result, nil
+}
+
+// Tree returns nil, the RbTree guarded by the SyncRbTree is not exposed to the iterations
+func (iterator *syncRbIterator) Tree() *RbTree {
+    return nil
+}
+
+// All returns a sequence of all items of the tree in ascending order
+// holding a read lock on the tree until the loop ends
+func (tree *SyncRbTree) All() iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.All()
+    })
+}
+
+// Backward returns a sequence of all items of the tree in descending order
+// holding a read lock on the tree until the loop ends
+func (tree *SyncRbTree) Backward() iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Backward()
+    })
+}
+
+// Range returns a sequence of the items of the tree in ascending order that the key of the item
+// is greater or equal to loKey and less or equal to hiKey holding a read lock on the tree until the loop ends
+func (tree *SyncRbTree) Range(loKey RbKey, hiKey RbKey) iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Range(loKey, hiKey)
+    })
+}
+
+// From returns a sequence of the items of the tree in ascending order that the key of the item
+// is greater or equal to the given key holding a read lock on the tree until the loop ends
+func (tree *SyncRbTree) From(key RbKey) iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.From(key)
+    })
+}
+
+// Until returns a sequence of the items of the tree in ascending order that the key of the item
+// is less than the given key holding a read lock on the tree until the loop ends
+func (tree *SyncRbTree) Until(key RbKey) iter.Seq2[RbKey, interface{}] {
+    return readLocked(&tree.lock, func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Until(key)
+    })
+}
+
+// readLocked wraps the sequence to hold a read lock on the lock while iterating,
+// the sequence is created under the lock as creating it reads the guarded tree
+func readLocked(lock *sync.RWMutex, create func() iter.Seq2[RbKey, interface{}]) iter.Seq2[RbKey, interface{}] {
+    return func(yield func(RbKey, interface{}) bool) {
+        lock.RLock()
+        defer lock.RUnlock()
+        create()(yield)
+    }
+}
+
+func (iterator *syncRbIterator) All() (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.All()
+}
+
+func (iterator *syncRbIterator) AllDesc() (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.AllDesc()
+}
+
+func (iterator *syncRbIterator) Between(loKey RbKey, hiKey RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.Between(loKey, hiKey)
+}
+
+func (iterator *syncRbIterator) BetweenDesc(loKey RbKey, hiKey RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.BetweenDesc(loKey, hiKey)
+}
+
+func (iterator *syncRbIterator) LessOrEqual(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.LessOrEqual(key)
+}
+
+func (iterator *syncRbIterator) LessOrEqualDesc(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.LessOrEqualDesc(key)
+}
+
+func (iterator *syncRbIterator) LessThan(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.LessThan(key)
+}
+
+func (iterator *syncRbIterator) LessThanDesc(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.LessThanDesc(key)
+}
+
+func (iterator *syncRbIterator) GreaterOrEqual(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.GreaterOrEqual(key)
+}
+
+func (iterator *syncRbIterator) GreaterOrEqualDesc(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.GreaterOrEqualDesc(key)
+}
+
+func (iterator *syncRbIterator) GreaterThan(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.GreaterThan(key)
+}
+
+func (iterator *syncRbIterator) GreaterThanDesc(key RbKey) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.GreaterThanDesc(key)
+}
+
+func (iterator *syncRbIterator) Prefix(prefix []byte) (int, error) {
+    iterator.owner.lock.RLock()
+    defer iterator.owner.lock.RUnlock()
+    return iterator.RbIterator.Prefix(prefix)
+}
@@ -0,0 +1,128 @@
+package rbt
+
+import (
+    "sync"
+    "testing"
+)
+
+func TestSyncRbTreeConcurrentAccess(t *testing.T) {
+    tree := NewSyncRbTree()
+    for i := 0; i < 1000; i++ {
+        key := IntKey(i)
+        tree.Insert(&key, i)
+    }
+
+    var wg sync.WaitGroup
+    for w := 0; w < 4; w++ {
+        wg.Add(1)
+        go func(w int) {
+            defer wg.Done()
+            for i := 0; i < 2000; i++ {
+                key := IntKey(1000 + (i * 4 + w) % 2000)
+                if i % 3 == 0 {
+                    tree.Delete(&key)
+                } else {
+                    tree.Insert(&key, i)
+                }
+            }
+        }(w)
+    }
+
+    for r := 0; r < 4; r++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+
+            iterator, err := tree.NewRbIterator(func(iterator RbIterator, key RbKey, value interface{}) {})
+            if err != nil {
+                t.Error(err)
+                return
+            }
+            for i := 0; i < 50; i++ {
+                if _, err := iterator.All(); err != nil {
+                    t.Error(err)
+                    return
+                }
+                loKey, hiKey := IntKey(0), IntKey(999)
+                if count, err := iterator.BetweenDesc(&loKey, &hiKey); err != nil || count != 1000 {
+                    t.Errorf("BetweenDesc() = %d, %v, want 1000", count, err)
+                    return
+                }
+
+                count := 0
+                for range tree.Range(&loKey, &hiKey) {
+                    count++
+                }
+                if count != 1000 {
+                    t.Errorf("Range() yielded %d items, want 1000", count)
+                    return
+                }
+
+                key := IntKey(i)
+                if value, ok := tree.Get(&key); !ok || value != i {
+                    t.Errorf("Get(%d) = %v, %v", i, value, ok)
+                    return
+                }
+                tree.CountBetween(&loKey, &hiKey)
+            }
+        }()
+    }
+    wg.Wait()
+
+    count := 0
+    for range tree.All() {
+        count++
+    }
+    if count != tree.Count() {
+        t.Fatalf("All() yielded %d items, Count() = %d", count, tree.Count())
+    }
+}
+
+func TestSyncRbTreeSequenceCreatedEarly(t *testing.T) {
+    tree := NewSyncRbTree()
+    loKey, hiKey := IntKey(0), IntKey(99)
+    seqs := []func(func(RbKey, interface{}) bool){
+        tree.All(), tree.Backward(), tree.Range(&loKey, &hiKey), tree.From(&loKey), tree.Until(&hiKey),
+    }
+
+    for i := 0; i < 10; i++ {
+        key := IntKey(i)
+        tree.Insert(&key, i)
+    }
+    for i, seq := range seqs {
+        count := 0
+        for range seq {
+            count++
+        }
+        if count != 10 {
+            t.Fatalf("sequence %d created before the inserts yielded %d items, want 10", i, count)
+        }
+    }
+}
+
+func TestSyncRbIteratorHidesTree(t *testing.T) {
+    tree := NewSyncRbTree()
+    for i := 0; i < 10; i++ {
+        key := IntKey(i)
+        tree.Insert(&key, i)
+    }
+
+    var iterator RbIterator
+    iterator, err := tree.NewRbIterator(func(current RbIterator, key RbKey, value interface{}) {
+        if current != iterator || current.Tree() != nil {
+            t.Errorf("callback got the unguarded iterator")
+        }
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if iterator.Tree() != nil {
+        t.Fatalf("Tree() exposes the unguarded RbTree")
+    }
+    if count, err := iterator.All(); err != nil || count != 10 {
+        t.Fatalf("All() = %d, %v, want 10", count, err)
+    }
+    if _, err = tree.NewRbIterator(nil); err == nil {
+        t.Fatalf("NewRbIterator(nil) succeeded")
+    }
+}
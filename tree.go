@@ -0,0 +1,334 @@
+package rbt
+
+import (
+    "cmp"
+)
+
+// Tree structure is the type-parameterized variant of RbTree
+// storing keys of type K and values of type V
+type Tree[K any, V any] struct {
+    root *treeNode[K, V]
+    count int
+    version uint32
+    compare func(a, b K) KeyComparison
+}
+
+// treeNode structure used for storing typed key and value pairs
+type treeNode[K any, V any] struct {
+    key K
+    value V
+    color byte
+    left, right *treeNode[K, V]
+}
+
+// NewTree creates a new Tree for the ordered key type K and returns its address
+func NewTree[K cmp.Ordered, V any]() *Tree[K, V] {
+    return &Tree[K, V]{
+        compare: compareOrdered[K],
+    }
+}
+
+// NewTreeWithComparator creates a new Tree ordering its keys with the given compare function
+// and returns its address
+func NewTreeWithComparator[K any, V any](compare func(a, b K) KeyComparison) *Tree[K, V] {
+    if compare == nil {
+        return nil
+    }
+    return &Tree[K, V]{
+        compare: compare,
+    }
+}
+
+// compareOrdered compares two ordered keys
+func compareOrdered[K cmp.Ordered](a, b K) KeyComparison {
+    return KeyComparison(cmp.Compare(a, b))
+}
+
+// newTreeNode creates a new treeNode and returns its address
+func newTreeNode[K any, V any](key K, value V) *treeNode[K, V] {
+    return &treeNode[K, V]{
+        key: key,
+        value: value,
+        color: red,
+    }
+}
+
+// isRed checks if node exists and its color is red
+func (node *treeNode[K, V]) isRed() bool {
+    return node != nil && node.color == red
+}
+
+// isBlack checks if node exists and its color is black
+func (node *treeNode[K, V]) isBlack() bool {
+    return node != nil && node.color == black
+}
+
+// min finds the smallest node key including the node
+func (node *treeNode[K, V]) min() *treeNode[K, V] {
+    if node != nil {
+        for node.left != nil {
+            node = node.left
+        }
+    }
+    return node
+}
+
+// max finds the greatest node key including the node
+func (node *treeNode[K, V]) max() *treeNode[K, V] {
+    if node != nil {
+        for node.right != nil {
+            node = node.right
+        }
+    }
+    return node
+}
+
+// colorFlip switchs the color of the node and its children from red to black or black to red
+func (node *treeNode[K, V]) colorFlip() {
+    node.color ^= black
+    node.left.color ^= black
+    node.right.color ^= black
+}
+
+// rotateLeft makes a right-leaning link lean to the left
+func (node *treeNode[K, V]) rotateLeft() *treeNode[K, V] {
+    child := node.right
+    node.right = child.left
+    child.left = node
+    child.color = node.color
+    node.color = red
+
+    return child
+}
+
+// rotateRight makes a left-leaning link lean to the right
+func (node *treeNode[K, V]) rotateRight() *treeNode[K, V] {
+    child := node.left
+    node.left = child.right
+    child.right = node
+    child.color = node.color
+    node.color = red
+
+    return child
+}
+
+// moveRedLeft makes node.left or one of its children red,
+// assuming that node is red and both children are black.
+func (node *treeNode[K, V]) moveRedLeft() *treeNode[K, V] {
+    node.colorFlip()
+    if node.right.left.isRed() {
+        node.right = node.right.rotateRight()
+        node = node.rotateLeft()
+        node.colorFlip()
+    }
+    return node
+}
+
+// moveRedRight makes node.right or one of its children red,
+// assuming that node is red and both children are black.
+func (node *treeNode[K, V]) moveRedRight() *treeNode[K, V] {
+    node.colorFlip()
+    if node.left.left.isRed() {
+        node = node.rotateRight()
+        node.colorFlip()
+    }
+    return node
+}
+
+// balance restores red-black tree invariant
+func (node *treeNode[K, V]) balance() *treeNode[K, V] {
+    if node.right.isRed() {
+        node = node.rotateLeft()
+    }
+    if node.left.isRed() && node.left.left.isRed() {
+        node = node.rotateRight()
+    }
+    if node.left.isRed() && node.right.isRed() {
+        node.colorFlip()
+    }
+    return node
+}
+
+// deleteMin removes the smallest key and associated value from the subtree
+func (node *treeNode[K, V]) deleteMin() *treeNode[K, V] {
+    if node.left == nil {
+        return nil
+    }
+    if node.left.isBlack() && !node.left.left.isRed() {
+        node = node.moveRedLeft()
+    }
+    node.left = node.left.deleteMin()
+    return node.balance()
+}
+
+// Count returns if count of the nodes stored.
+func (tree *Tree[K, V]) Count() int {
+    return tree.count
+}
+
+// IsEmpty returns if the tree has any node.
+func (tree *Tree[K, V]) IsEmpty() bool {
+    return tree.root == nil
+}
+
+// Min returns the smallest key in the tree and 'true',
+// otherwise returns 'false' as third return param if the tree is empty
+func (tree *Tree[K, V]) Min() (key K, value V, ok bool) {
+    if tree.root != nil {
+        result := tree.root.min()
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Max returns the largest key in the tree and 'true',
+// otherwise returns 'false' as third return param if the tree is empty
+func (tree *Tree[K, V]) Max() (key K, value V, ok bool) {
+    if tree.root != nil {
+        result := tree.root.max()
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Floor returns the largest key in the tree less than or equal to key
+func (tree *Tree[K, V]) Floor(key K) (floorKey K, value V, ok bool) {
+    var result *treeNode[K, V]
+    for node := tree.root; node != nil; {
+        switch tree.compare(key, node.key) {
+        case KeysAreEqual:
+            return node.key, node.value, true
+        case KeyIsLess:
+            node = node.left
+        default:
+            result = node
+            node = node.right
+        }
+    }
+    if result != nil {
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Ceiling returns the smallest key in the tree greater than or equal to key
+func (tree *Tree[K, V]) Ceiling(key K) (ceilingKey K, value V, ok bool) {
+    var result *treeNode[K, V]
+    for node := tree.root; node != nil; {
+        switch tree.compare(key, node.key) {
+        case KeysAreEqual:
+            return node.key, node.value, true
+        case KeyIsGreater:
+            node = node.right
+        default:
+            result = node
+            node = node.left
+        }
+    }
+    if result != nil {
+        return result.key, result.value, true
+    }
+    return
+}
+
+// Get returns the stored value if key found and 'true',
+// otherwise returns 'false' with second return param if key not found
+func (tree *Tree[K, V]) Get(key K) (value V, ok bool) {
+    node := tree.find(key)
+    if node != nil {
+        return node.value, true
+    }
+    return
+}
+
+// find returns the node if key found, otherwise returns nil
+func (tree *Tree[K, V]) find(key K) *treeNode[K, V] {
+    for node := tree.root; node != nil; {
+        switch tree.compare(key, node.key) {
+        case KeyIsLess:
+            node = node.left
+        case KeyIsGreater:
+            node = node.right
+        default:
+            return node
+        }
+    }
+    return nil
+}
+
+// Exists returns 'true' if key found, otherwise returns 'false'
+func (tree *Tree[K, V]) Exists(key K) bool {
+    return tree.find(key) != nil
+}
+
+// Insert inserts the given key and value into the tree
+func (tree *Tree[K, V]) Insert(key K, value V) {
+    tree.version++
+    tree.root = tree.insertNode(tree.root, key, value)
+    tree.root.color = black
+}
+
+// insertNode adds the given key and value into the node
+func (tree *Tree[K, V]) insertNode(node *treeNode[K, V], key K, value V) *treeNode[K, V] {
+    if node == nil {
+        tree.count++
+        return newTreeNode(key, value)
+    }
+
+    switch tree.compare(key, node.key) {
+    case KeyIsLess:
+        node.left = tree.insertNode(node.left, key, value)
+    case KeyIsGreater:
+        node.right = tree.insertNode(node.right, key, value)
+    default:
+        node.value = value
+    }
+    return node.balance()
+}
+
+// Delete deletes the given key from the tree
+func (tree *Tree[K, V]) Delete(key K) {
+    if tree.find(key) == nil {
+        return
+    }
+
+    tree.version++
+    tree.root = tree.deleteNode(tree.root, key)
+    if tree.root != nil {
+        tree.root.color = black
+    }
+    tree.count--
+}
+
+// deleteNode deletes the given key from the node,
+// assuming that the key exists in the subtree
+func (tree *Tree[K, V]) deleteNode(node *treeNode[K, V], key K) *treeNode[K, V] {
+    if tree.compare(key, node.key) == KeyIsLess {
+        if node.left.isBlack() && !node.left.left.isRed() {
+            node = node.moveRedLeft()
+        }
+        node.left = tree.deleteNode(node.left, key)
+    } else {
+        if node.left.isRed() {
+            node = node.rotateRight()
+        }
+
+        if tree.compare(key, node.key) == KeysAreEqual && node.right == nil {
+            return nil
+        }
+
+        if node.right.isBlack() && !node.right.left.isRed() {
+            node = node.moveRedRight()
+        }
+
+        if tree.compare(key, node.key) != KeysAreEqual {
+            node.right = tree.deleteNode(node.right, key)
+        } else {
+            rm := node.right.min()
+            node.key = rm.key
+            node.value = rm.value
+            node.right = node.right.deleteMin()
+        }
+    }
+    return node.balance()
+}
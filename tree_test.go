@@ -0,0 +1,226 @@
+package rbt
+
+import (
+    "fmt"
+    "math/rand"
+    "runtime"
+    "strings"
+    "testing"
+    "time"
+)
+
+// checkTree validates the left-leaning red-black invariants of the subtree
+// and returns its black height
+func checkTree[K any, V any](t *testing.T, tree *Tree[K, V], node *treeNode[K, V]) int {
+    if node == nil {
+        return 0
+    }
+    if node.right.isRed() {
+        t.Fatalf("right-leaning red link at %v", node.key)
+    }
+    if node.isRed() && node.left.isRed() {
+        t.Fatalf("two red links in a row at %v", node.key)
+    }
+    if node.left != nil && tree.compare(node.left.key, node.key) != KeyIsLess {
+        t.Fatalf("left key %v is not less than %v", node.left.key, node.key)
+    }
+    if node.right != nil && tree.compare(node.right.key, node.key) != KeyIsGreater {
+        t.Fatalf("right key %v is not greater than %v", node.right.key, node.key)
+    }
+
+    lh, rh := checkTree(t, tree, node.left), checkTree(t, tree, node.right)
+    if lh != rh {
+        t.Fatalf("black height mismatch at %v: %d != %d", node.key, lh, rh)
+    }
+    if node.isBlack() {
+        lh++
+    }
+    return lh
+}
+
+func TestTreeInsertDeleteAndGet(t *testing.T) {
+    fmt.Println("\nTestTreeInsertDeleteAndGet\n~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~~")
+
+    mem1 := new(runtime.MemStats)
+    runtime.ReadMemStats(mem1)
+
+    t1 := time.Now()
+
+    tree := NewTree[int, int]()
+    for i := 0; i < 1000000; i++ {
+        tree.Insert(i, 10 + i)
+    }
+
+    t2 := time.Now()
+    fmt.Printf("Insert time: %.5f sec\n", float64(t2.Sub(t1).Nanoseconds())/float64(time.Second.Nanoseconds()))
+
+    count := 0
+    for i := 0; i < 1500000; i++ {
+        if value, ok := tree.Get(i); ok {
+            if value != 10 + i {
+                t.Fatalf("Get(%d) = %d, want %d", i, value, 10 + i)
+            }
+            count++
+        }
+    }
+    if count != 1000000 {
+        t.Fatalf("found %d keys, want 1000000", count)
+    }
+
+    t3 := time.Now()
+    fmt.Printf("Search time: %.5f sec with count %d\n", float64(t3.Sub(t2).Nanoseconds())/float64(time.Second.Nanoseconds()), count)
+
+    for i := 1; i < 1000000; i++ {
+        tree.Delete(i)
+    }
+
+    t4 := time.Now()
+    fmt.Printf("Delete time: %.5f sec\n", float64(t4.Sub(t3).Nanoseconds())/float64(time.Second.Nanoseconds()))
+
+    if tree.Count() != 1 || !tree.Exists(0) {
+        t.Fatalf("Count() = %d after deletes, want 1", tree.Count())
+    }
+
+    mem2 := new(runtime.MemStats)
+    runtime.ReadMemStats(mem2)
+    if mem2.Alloc <= mem1.Alloc {
+        fmt.Printf("Mem allocated: 0 MB\n")
+    } else {
+        fmt.Printf("Mem allocated: %3.3f MB\n", float64(mem2.Alloc - mem1.Alloc)/(1024*1024))
+    }
+}
+
+func TestTreeRandomized(t *testing.T) {
+    tree := NewTree[int, string]()
+    expected := make(map[int]string)
+
+    rnd := rand.New(rand.NewSource(1))
+    for i := 0; i < 20000; i++ {
+        key := rnd.Intn(2000)
+        if rnd.Intn(3) == 0 {
+            tree.Delete(key)
+            delete(expected, key)
+        } else {
+            value := fmt.Sprint(i)
+            tree.Insert(key, value)
+            expected[key] = value
+        }
+        if i % 1000 == 0 {
+            checkTree(t, tree, tree.root)
+        }
+    }
+    checkTree(t, tree, tree.root)
+
+    if tree.Count() != len(expected) {
+        t.Fatalf("Count() = %d, want %d", tree.Count(), len(expected))
+    }
+    for key, value := range expected {
+        if got, ok := tree.Get(key); !ok || got != value {
+            t.Fatalf("Get(%d) = %q, %v, want %q", key, got, ok, value)
+        }
+    }
+
+    for i := -1; i <= 2000; i++ {
+        floorKey, _, floorOk := tree.Floor(i)
+        ceilingKey, _, ceilingOk := tree.Ceiling(i)
+
+        wantFloor, wantCeiling := -1, -1
+        for key := range expected {
+            if key <= i && key > wantFloor {
+                wantFloor = key
+            }
+            if key >= i && (wantCeiling == -1 || key < wantCeiling) {
+                wantCeiling = key
+            }
+        }
+        if floorOk != (wantFloor != -1) || (floorOk && floorKey != wantFloor) {
+            t.Fatalf("Floor(%d) = %d, %v, want %d", i, floorKey, floorOk, wantFloor)
+        }
+        if ceilingOk != (wantCeiling != -1) || (ceilingOk && ceilingKey != wantCeiling) {
+            t.Fatalf("Ceiling(%d) = %d, %v, want %d", i, ceilingKey, ceilingOk, wantCeiling)
+        }
+    }
+}
+
+func TestTreeWithComparator(t *testing.T) {
+    tree := NewTreeWithComparator[string, int](func(a, b string) KeyComparison {
+        return KeyComparison(strings.Compare(strings.ToLower(a), strings.ToLower(b)))
+    })
+
+    tree.Insert("b", 1)
+    tree.Insert("A", 2)
+    tree.Insert("B", 3)
+
+    if tree.Count() != 2 {
+        t.Fatalf("Count() = %d, want 2", tree.Count())
+    }
+    if key, value, _ := tree.Min(); key != "A" || value != 2 {
+        t.Fatalf("Min() = %q, %d, want \"A\", 2", key, value)
+    }
+    if value, ok := tree.Get("b"); !ok || value != 3 {
+        t.Fatalf("Get(\"b\") = %d, %v, want 3", value, ok)
+    }
+}
+
+func TestTreeIterator(t *testing.T) {
+    tree := NewTree[int, int]()
+    for i := 1; i <= 100; i++ {
+        tree.Insert(i, i * 10)
+    }
+
+    var keys []int
+    iterator, err := tree.NewIterator(func(iterator *TreeIterator[int, int], key int, value int) {
+        if value != key * 10 {
+            t.Fatalf("value %d for key %d", value, key)
+        }
+        keys = append(keys, key)
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name string
+        run func() (int, error)
+        first, last, count int
+    }{
+        {"All", iterator.All, 1, 100, 100},
+        {"Between", func() (int, error) { return iterator.Between(60, 20) }, 20, 60, 41},
+        {"LessThan", func() (int, error) { return iterator.LessThan(50) }, 1, 49, 49},
+        {"LessOrEqual", func() (int, error) { return iterator.LessOrEqual(50) }, 1, 50, 50},
+        {"GreaterThan", func() (int, error) { return iterator.GreaterThan(50) }, 51, 100, 50},
+        {"GreaterOrEqual", func() (int, error) { return iterator.GreaterOrEqual(50) }, 50, 100, 51},
+    }
+
+    for _, test := range tests {
+        keys = keys[:0]
+        count, err := test.run()
+        if err != nil {
+            t.Fatalf("%s: %v", test.name, err)
+        }
+        if count != test.count || len(keys) != test.count || keys[0] != test.first || keys[len(keys)-1] != test.last {
+            t.Fatalf("%s: got %d keys %v", test.name, count, keys)
+        }
+        for i := 1; i < len(keys); i++ {
+            if keys[i-1] >= keys[i] {
+                t.Fatalf("%s: keys not ascending %v", test.name, keys)
+            }
+        }
+    }
+
+    modifying, _ := tree.NewIterator(func(iterator *TreeIterator[int, int], key int, value int) {
+        tree.Insert(1000 + key, key)
+    })
+    if _, err := modifying.All(); err != ErrEnumeratorModified {
+        t.Fatalf("All() while modifying returned %v, want %v", err, ErrEnumeratorModified)
+    }
+
+    closing, _ := tree.NewIterator(func(iterator *TreeIterator[int, int], key int, value int) {
+        if key == 10 {
+            iterator.Close()
+        }
+    })
+    if count, err := closing.All(); err != nil || count != 10 {
+        t.Fatalf("All() with Close returned %d, %v, want 10", count, err)
+    }
+}
@@ -0,0 +1,164 @@
+package rbt
+
+// TreeIterationCallback is the function used to by the TreeIterator
+// with will be called on iteration match
+type TreeIterationCallback[K any, V any] func(iterator *TreeIterator[K, V], key K, value V)
+
+// TreeIterator structure used for iterating on a Tree
+type TreeIterator[K any, V any] struct {
+    tree *Tree[K, V]
+    count int
+    state int32
+    version uint32
+    callback TreeIterationCallback[K, V]
+}
+
+// treeBound structure used for limiting the iteration range of a TreeIterator
+type treeBound[K any] struct {
+    key K
+    inclusive bool
+}
+
+// NewIterator creates a new iterator for the given Tree
+func (tree *Tree[K, V]) NewIterator(callback TreeIterationCallback[K, V]) (*TreeIterator[K, V], error) {
+    if tree == nil {
+        return nil, ArgumentNilError("tree")
+    }
+    if callback == nil {
+        return nil, ArgumentNilError("callback")
+    }
+
+    return &TreeIterator[K, V]{
+        tree: tree,
+        version: tree.version,
+        callback: callback,
+        state: iteratorReady,
+    }, nil
+}
+
+// Tree returns the Tree that the iterator is iterating on
+func (iterator *TreeIterator[K, V]) Tree() *Tree[K, V] {
+    return iterator.tree
+}
+
+// CurrentCount gives the count of the items that match the iteration case
+func (iterator *TreeIterator[K, V]) CurrentCount() int {
+    return iterator.count
+}
+
+// Close closes the current iteration, so the iteration stops iterating
+func (iterator *TreeIterator[K, V]) Close() {
+    iterator.state = iteratorClosed
+    iterator.tree = nil
+}
+
+// Closed gives the state of the iterator, 'true' if closed
+func (iterator *TreeIterator[K, V]) Closed() bool {
+    return iterator.state == iteratorClosed
+}
+
+// All iterates on all items of the Tree
+func (iterator *TreeIterator[K, V]) All() (int, error) {
+    return iterator.iterate(nil, nil)
+}
+
+// Between iterates on the items of the Tree that the key of the item
+// is greater or equal to loKey and less or equal to hiKey
+func (iterator *TreeIterator[K, V]) Between(loKey K, hiKey K) (int, error) {
+    if iterator.tree != nil && iterator.tree.compare(loKey, hiKey) == KeyIsGreater {
+        loKey, hiKey = hiKey, loKey
+    }
+    return iterator.iterate(&treeBound[K]{key: loKey, inclusive: true}, &treeBound[K]{key: hiKey, inclusive: true})
+}
+
+// LessOrEqual iterates on the items of the Tree that the key of the item
+// is less or equal to the given key
+func (iterator *TreeIterator[K, V]) LessOrEqual(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key, inclusive: true})
+}
+
+// LessThan iterates on the items of the Tree that the key of the item
+// is less than the given key
+func (iterator *TreeIterator[K, V]) LessThan(key K) (int, error) {
+    return iterator.iterate(nil, &treeBound[K]{key: key})
+}
+
+// GreaterOrEqual iterates on the items of the Tree that the key of the item
+// is greater or equal to the given key
+func (iterator *TreeIterator[K, V]) GreaterOrEqual(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key, inclusive: true}, nil)
+}
+
+// GreaterThan iterates on the items of the Tree that the key of the item
+// is greater than the given key
+func (iterator *TreeIterator[K, V]) GreaterThan(key K) (int, error) {
+    return iterator.iterate(&treeBound[K]{key: key}, nil)
+}
+
+// iterate walks on the items of the Tree between the given bounds,
+// a nil bound leaves that side of the range open
+func (iterator *TreeIterator[K, V]) iterate(lo, hi *treeBound[K]) (int, error) {
+    switch iterator.state {
+    case iterWalking:
+        return 0, ErrIteratorAlreadyRunning
+    case iteratorClosed:
+        return 0, ErrIteratorClosed
+    case iteratorUninitialized:
+        return 0, ErrIteratorUninitialized
+    }
+    if iterator.tree == nil {
+        return 0, ErrIteratorClosed
+    }
+
+    iterator.count = 0
+    iterator.state = iterWalking
+    iterator.version = iterator.tree.version
+
+    err := iterator.walk(iterator.tree.root, lo, hi)
+    if iterator.state == iterWalking {
+        iterator.state = iteratorReady
+    }
+    return iterator.count, err
+}
+
+// walk iterates on the subtree rooted at node in ascending order
+func (iterator *TreeIterator[K, V]) walk(node *treeNode[K, V], lo, hi *treeBound[K]) error {
+    for node != nil {
+        if iterator.state != iterWalking {
+            return nil
+        }
+        tree := iterator.tree
+        if tree == nil || iterator.version != tree.version {
+            return ErrEnumeratorModified
+        }
+
+        aboveLo := true
+        if lo != nil {
+            cmp := tree.compare(node.key, lo.key)
+            aboveLo = cmp == KeyIsGreater || (lo.inclusive && cmp == KeysAreEqual)
+        }
+        belowHi := true
+        if hi != nil {
+            cmp := tree.compare(node.key, hi.key)
+            belowHi = cmp == KeyIsLess || (hi.inclusive && cmp == KeysAreEqual)
+        }
+
+        if aboveLo {
+            if err := iterator.walk(node.left, lo, hi); err != nil || iterator.state != iterWalking {
+                return err
+            }
+            if iterator.version != tree.version {
+                return ErrEnumeratorModified
+            }
+            if belowHi {
+                iterator.count++
+                iterator.callback(iterator, node.key, node.value)
+            }
+        }
+        if !belowHi {
+            return nil
+        }
+        node = node.right
+    }
+    return nil
+}
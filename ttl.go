@@ -0,0 +1,359 @@
+package rbt
+
+import (
+    "iter"
+    "math"
+    "sync"
+    "time"
+)
+
+// Clock function returns the current time used for the expiry of the TTLRbTree entries
+type Clock func() time.Time
+
+// ttlEntry structure is the value stored in the inner tree of a TTLRbTree,
+// expires is the expiry time in unix nanoseconds and only set if the entry is expiring,
+// so any time before or after 1970 can be an expiry time
+type ttlEntry struct {
+    value interface{}
+    expires int64
+    expiring bool
+}
+
+// ttlExpiryKey structure is the key of the expiry index ordering the entries by their expiry time and key
+type ttlExpiryKey struct {
+    expires int64
+    key RbKey
+}
+
+// ComparedTo compares the expiry key with the given expiry key
+func (ekey *ttlExpiryKey) ComparedTo(key RbKey) KeyComparison {
+    other := key.(*ttlExpiryKey)
+    switch {
+    case ekey.expires < other.expires:
+        return KeyIsLess
+    case ekey.expires > other.expires:
+        return KeyIsGreater
+    }
+    return ekey.key.ComparedTo(other.key)
+}
+
+// TTLRbTree structure is the concurrency-safe RbTree which entries may expire after a time-to-live.
+// The expired entries are invisible to the reads and iterations, they are removed lazily
+// by the operations on the tree, by Reap and by the background reaper if started.
+// A secondary index ordered by the expiry times keeps the removal cost proportional
+// to the count of the expired entries.
+//
+// All the keys of the tree must be of the same type as the expiry index compares them,
+// Insert panics and TryInsert returns ErrKeyTypeMismatch on a key of another type.
+// All the operations hold a lock on the tree, so the range loops must not
+// modify or re-enter the same TTLRbTree.
+type TTLRbTree struct {
+    lock sync.Mutex
+    tree *RbTree
+    expiries *RbTree
+    next int64
+    hasNext bool
+    clock Clock
+    stop chan struct{}
+    done chan struct{}
+}
+
+// NewTTLRbTree creates a new TTLRbTree using the system clock and returns its address
+func NewTTLRbTree() *TTLRbTree {
+    return NewTTLRbTreeWithClock(time.Now)
+}
+
+// NewTTLRbTreeWithClock creates a new TTLRbTree using the given clock for the expiry times and returns its address,
+// returns nil if the clock is nil
+func NewTTLRbTreeWithClock(clock Clock) *TTLRbTree {
+    if clock == nil {
+        return nil
+    }
+    return &TTLRbTree{
+        tree: NewRbTree(),
+        expiries: NewRbTree(),
+        clock: clock,
+    }
+}
+
+// now returns the current time of the tree clock in unix nanoseconds
+func (tree *TTLRbTree) now() int64 {
+    return tree.clock().UnixNano()
+}
+
+// expire removes the entries expired at the given time in their expiry order, returns the count of the removed entries
+func (tree *TTLRbTree) expire(now int64) int {
+    if !tree.hasNext || tree.next > now {
+        return 0
+    }
+
+    count := 0
+    for {
+        key, _ := tree.expiries.Min()
+        if key == nil {
+            tree.hasNext = false
+            break
+        }
+        ekey := key.(*ttlExpiryKey)
+        if ekey.expires > now {
+            tree.next = ekey.expires
+            break
+        }
+        tree.expiries.Delete(ekey)
+        tree.tree.Delete(ekey.key)
+        count++
+    }
+    return count
+}
+
+// unindex removes the expiry of the entry from the expiry index
+func (tree *TTLRbTree) unindex(key RbKey, entry *ttlEntry) {
+    if !entry.expiring {
+        return
+    }
+    tree.expiries.Delete(&ttlExpiryKey{expires: entry.expires, key: key})
+    if entry.expires == tree.next {
+        tree.hasNext = false
+        if min, _ := tree.expiries.Min(); min != nil {
+            tree.next, tree.hasNext = min.(*ttlExpiryKey).expires, true
+        }
+    }
+}
+
+// entry returns the unexpired entry of the key after removing the expired entries,
+// nil if key not found or the key type differs from the type of the stored keys
+func (tree *TTLRbTree) entry(key RbKey) *ttlEntry {
+    tree.expire(tree.now())
+    if tree.tree.checkKeyType(key) != nil {
+        return nil
+    }
+    if value, ok := tree.tree.Get(key); ok {
+        return value.(*ttlEntry)
+    }
+    return nil
+}
+
+// Count returns the count of the unexpired entries.
+func (tree *TTLRbTree) Count() int {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    tree.expire(tree.now())
+    return tree.tree.Count()
+}
+
+// IsEmpty returns if the tree has any unexpired entry.
+func (tree *TTLRbTree) IsEmpty() bool {
+    return tree.Count() == 0
+}
+
+// Insert inserts the given key and value into the tree without expiry,
+// removes the expiry of the key if exists. Panics with ErrKeyTypeMismatch
+// if the key type differs from the type of the stored keys.
+func (tree *TTLRbTree) Insert(key RbKey, value interface{}) {
+    tree.InsertWithTTL(key, value, 0)
+}
+
+// InsertWithTTL inserts the given key and value into the tree expiring after the ttl,
+// the entry does not expire if the ttl is not positive. The expiry time saturates
+// at the largest unix nanoseconds time instead of overflowing.
+// Panics with ErrKeyTypeMismatch if the key type differs from the type of the stored keys.
+func (tree *TTLRbTree) InsertWithTTL(key RbKey, value interface{}, ttl time.Duration) {
+    if err := tree.TryInsertWithTTL(key, value, ttl); err == ErrKeyTypeMismatch {
+        panic(err)
+    }
+}
+
+// TryInsert inserts the given key and value into the tree without expiry,
+// returns ErrKeyTypeMismatch instead of inserting if the key type differs from the type of the stored keys
+func (tree *TTLRbTree) TryInsert(key RbKey, value interface{}) error {
+    return tree.TryInsertWithTTL(key, value, 0)
+}
+
+// TryInsertWithTTL inserts the given key and value into the tree expiring after the ttl,
+// returns ErrKeyTypeMismatch instead of inserting if the key type differs from the type of the stored keys
+func (tree *TTLRbTree) TryInsertWithTTL(key RbKey, value interface{}, ttl time.Duration) error {
+    if key == nil {
+        return ArgumentNilError("key")
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+
+    now := tree.now()
+    tree.expire(now)
+    if err := tree.tree.checkKeyType(key); err != nil {
+        return err
+    }
+    if old, ok := tree.tree.Get(key); ok {
+        tree.unindex(key, old.(*ttlEntry))
+    }
+
+    entry := &ttlEntry{value: value}
+    if ttl > 0 {
+        entry.expires, entry.expiring = math.MaxInt64, true
+        if now < 0 || int64(ttl) <= math.MaxInt64 - now {
+            entry.expires = now + int64(ttl)
+        }
+        tree.expiries.Insert(&ttlExpiryKey{expires: entry.expires, key: key}, nil)
+        if !tree.hasNext || entry.expires < tree.next {
+            tree.next, tree.hasNext = entry.expires, true
+        }
+    }
+    tree.tree.Insert(key, entry)
+    return nil
+}
+
+// Get returns the stored value if key found and not expired and 'true',
+// otherwise returns 'false' with second return param
+func (tree *TTLRbTree) Get(key RbKey) (interface{}, bool) {
+    if key == nil {
+        return nil, false
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    if entry := tree.entry(key); entry != nil {
+        return entry.value, true
+    }
+    return nil, false
+}
+
+// Exists returns 'true' if key found and not expired, otherwise returns 'false'
+func (tree *TTLRbTree) Exists(key RbKey) bool {
+    _, ok := tree.Get(key)
+    return ok
+}
+
+// ExpiresAt returns the expiry time of the key and 'true' if key found and not expired,
+// the returned time is zero if the entry does not expire
+func (tree *TTLRbTree) ExpiresAt(key RbKey) (time.Time, bool) {
+    if key == nil {
+        return time.Time{}, false
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    entry := tree.entry(key)
+    if entry == nil {
+        return time.Time{}, false
+    }
+    if !entry.expiring {
+        return time.Time{}, true
+    }
+    return time.Unix(0, entry.expires), true
+}
+
+// Delete deletes the given key from the tree
+func (tree *TTLRbTree) Delete(key RbKey) {
+    if key == nil {
+        return
+    }
+
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    if entry := tree.entry(key); entry != nil {
+        tree.unindex(key, entry)
+        tree.tree.Delete(key)
+    }
+}
+
+// Reap removes the expired entries from the tree, returns the count of the removed entries
+func (tree *TTLRbTree) Reap() int {
+    tree.lock.Lock()
+    defer tree.lock.Unlock()
+    return tree.expire(tree.now())
+}
+
+// StartReaper starts a background goroutine calling Reap at every interval,
+// restarts the reaper if it is already running and only stops it if the interval is not positive.
+// The interval is measured by the system time regardless of the clock of the tree.
+func (tree *TTLRbTree) StartReaper(interval time.Duration) {
+    var stop, done chan struct{}
+    if interval > 0 {
+        stop, done = make(chan struct{}), make(chan struct{})
+    }
+
+    // the old reaper is replaced under the lock, so the concurrent calls
+    // never leave more than one reaper running
+    tree.lock.Lock()
+    oldStop, oldDone := tree.stop, tree.done
+    tree.stop, tree.done = stop, done
+    tree.lock.Unlock()
+
+    // the old reaper is waited without the lock, as it takes the lock for reaping
+    if oldStop != nil {
+        close(oldStop)
+        <-oldDone
+    }
+    if stop == nil {
+        return
+    }
+
+    go func() {
+        defer close(done)
+
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-stop:
+                return
+            case <-ticker.C:
+                tree.Reap()
+            }
+        }
+    }()
+}
+
+// StopReaper stops the background reaper and waits for it to exit, does nothing if the reaper is not running
+func (tree *TTLRbTree) StopReaper() {
+    tree.lock.Lock()
+    stop, done := tree.stop, tree.done
+    tree.stop, tree.done = nil, nil
+    tree.lock.Unlock()
+
+    if stop != nil {
+        close(stop)
+        <-done
+    }
+}
+
+// All returns a sequence of all unexpired items of the tree in ascending order
+// holding the lock on the tree until the loop ends
+func (tree *TTLRbTree) All() iter.Seq2[RbKey, interface{}] {
+    return tree.unexpired(func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.All()
+    })
+}
+
+// Backward returns a sequence of all unexpired items of the tree in descending order
+// holding the lock on the tree until the loop ends
+func (tree *TTLRbTree) Backward() iter.Seq2[RbKey, interface{}] {
+    return tree.unexpired(func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Backward()
+    })
+}
+
+// Range returns a sequence of the unexpired items of the tree in ascending order that the key of the item
+// is greater or equal to loKey and less or equal to hiKey holding the lock on the tree until the loop ends
+func (tree *TTLRbTree) Range(loKey RbKey, hiKey RbKey) iter.Seq2[RbKey, interface{}] {
+    return tree.unexpired(func() iter.Seq2[RbKey, interface{}] {
+        return tree.tree.Range(loKey, hiKey)
+    })
+}
+
+// unexpired wraps the sequence of the inner tree to hold the lock on the tree while iterating,
+// the entries expired when the loop starts are removed before the walk
+func (tree *TTLRbTree) unexpired(seq func() iter.Seq2[RbKey, interface{}]) iter.Seq2[RbKey, interface{}] {
+    return func(yield func(RbKey, interface{}) bool) {
+        tree.lock.Lock()
+        defer tree.lock.Unlock()
+
+        tree.expire(tree.now())
+        for key, value := range seq() {
+            if !yield(key, value.(*ttlEntry).value) {
+                return
+            }
+        }
+    }
+}
@@ -0,0 +1,261 @@
+package rbt
+
+import (
+    "math"
+    "runtime"
+    "sync"
+    "testing"
+    "time"
+)
+
+// fakeClock structure is the manually advanced clock of the TTLRbTree tests
+type fakeClock struct {
+    lock sync.Mutex
+    now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+    clock.lock.Lock()
+    defer clock.lock.Unlock()
+    return clock.now
+}
+
+func (clock *fakeClock) Advance(d time.Duration) {
+    clock.lock.Lock()
+    defer clock.lock.Unlock()
+    clock.now = clock.now.Add(d)
+}
+
+func TestTTLExpiry(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(1000, 0)}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+
+    for i := 0; i < 10; i++ {
+        tree.InsertWithTTL(newKey(IntKey(i)), i, time.Duration(i + 1) * time.Second)
+    }
+    tree.Insert(newKey(IntKey(100)), 100)
+
+    if count := tree.Count(); count != 11 {
+        t.Fatalf("Count() = %d, want 11", count)
+    }
+    if at, ok := tree.ExpiresAt(newKey(IntKey(2))); !ok || !at.Equal(time.Unix(1003, 0)) {
+        t.Fatalf("ExpiresAt(2) = %v, %v", at, ok)
+    }
+    if at, ok := tree.ExpiresAt(newKey(IntKey(100))); !ok || !at.IsZero() {
+        t.Fatalf("ExpiresAt(100) = %v, %v, want zero time", at, ok)
+    }
+
+    clock.Advance(3 * time.Second)
+    if tree.Exists(newKey(IntKey(2))) {
+        t.Fatalf("expired key 2 exists")
+    }
+    if value, ok := tree.Get(newKey(IntKey(3))); !ok || value != 3 {
+        t.Fatalf("Get(3) = %v, %v", value, ok)
+    }
+
+    want := []int{3, 4, 5, 6, 7, 8, 9, 100}
+    i := 0
+    for key, value := range tree.All() {
+        if *key.(*IntKey) != IntKey(want[i]) || value != want[i] {
+            t.Fatalf("All() yielded %v, %v, want %d", key, value, want[i])
+        }
+        i++
+    }
+    if i != len(want) {
+        t.Fatalf("All() yielded %d items, want %d", i, len(want))
+    }
+
+    // refreshing the ttl moves the expiry of the key
+    tree.InsertWithTTL(newKey(IntKey(3)), 33, 10 * time.Second)
+    // inserting without ttl removes the expiry of the key
+    tree.Insert(newKey(IntKey(4)), 44)
+    tree.Delete(newKey(IntKey(5)))
+
+    clock.Advance(5 * time.Second)
+    if removed := tree.Reap(); removed != 2 {
+        t.Fatalf("Reap() = %d, want 2", removed)
+    }
+    if tree.expiries.Count() != 3 {
+        t.Fatalf("expiry index holds %d keys, want 3", tree.expiries.Count())
+    }
+
+    i = 0
+    want = []int{100, 9, 8, 44, 33}
+    for _, value := range tree.Backward() {
+        if value != want[i] {
+            t.Fatalf("Backward() yielded %v, want %d", value, want[i])
+        }
+        i++
+    }
+
+    clock.Advance(10 * time.Second)
+    i = 0
+    for key := range tree.Range(newKey(IntKey(0)), newKey(IntKey(10))) {
+        if *key.(*IntKey) != 4 {
+            t.Fatalf("Range() yielded %v after the expiring keys expired", key)
+        }
+        i++
+    }
+    if i != 1 {
+        t.Fatalf("Range() yielded %d items, want 1", i)
+    }
+    if count := tree.Count(); count != 2 || tree.expiries.Count() != 0 || tree.hasNext {
+        t.Fatalf("Count() = %d, index %d, has next %v", count, tree.expiries.Count(), tree.hasNext)
+    }
+}
+
+func TestTTLSameExpiry(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+    for i := 0; i < 100; i++ {
+        tree.InsertWithTTL(newKey(IntKey(i)), i, time.Minute)
+    }
+    tree.Delete(newKey(IntKey(50)))
+    if tree.expiries.Count() != 99 {
+        t.Fatalf("expiry index holds %d keys, want 99", tree.expiries.Count())
+    }
+
+    clock.Advance(time.Minute)
+    if !tree.IsEmpty() {
+        t.Fatalf("tree is not empty after the keys expired")
+    }
+}
+
+func TestTTLReaper(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, 0)}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+    for i := 0; i < 100; i++ {
+        tree.InsertWithTTL(newKey(IntKey(i)), i, time.Second)
+    }
+
+    tree.StartReaper(time.Millisecond)
+    defer tree.StopReaper()
+
+    clock.Advance(time.Second)
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        tree.lock.Lock()
+        count := tree.tree.Count()
+        tree.lock.Unlock()
+        if count == 0 {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatalf("reaper left %d expired keys", count)
+        }
+        time.Sleep(time.Millisecond)
+    }
+
+    tree.StopReaper()
+    tree.StartReaper(0)
+    if tree.stop != nil {
+        t.Fatalf("reaper started with a non-positive interval")
+    }
+}
+
+func TestTTLSaturatedExpiry(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(1000, 0)}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+    tree.InsertWithTTL(newKey(IntKey(1)), 1, time.Duration(math.MaxInt64))
+    tree.InsertWithTTL(newKey(IntKey(2)), 2, time.Second)
+
+    if at, ok := tree.ExpiresAt(newKey(IntKey(1))); !ok || at.UnixNano() != math.MaxInt64 {
+        t.Fatalf("ExpiresAt(1) = %v, %v, want the largest time", at, ok)
+    }
+    clock.Advance(time.Hour)
+    if tree.Reap() != 1 || !tree.Exists(newKey(IntKey(1))) {
+        t.Fatalf("entry with the largest ttl expired")
+    }
+}
+
+func TestTTLClockBefore1970(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(0, -int64(2 * time.Second))}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+    tree.InsertWithTTL(newKey(IntKey(1)), 1, 2 * time.Second)
+    tree.InsertWithTTL(newKey(IntKey(2)), 2, time.Second)
+    tree.Insert(newKey(IntKey(3)), 3)
+
+    if at, ok := tree.ExpiresAt(newKey(IntKey(1))); !ok || at.UnixNano() != 0 {
+        t.Fatalf("ExpiresAt(1) = %v, %v, want the unix epoch", at, ok)
+    }
+    if at, ok := tree.ExpiresAt(newKey(IntKey(3))); !ok || !at.IsZero() {
+        t.Fatalf("ExpiresAt(3) = %v, %v, want zero time", at, ok)
+    }
+
+    clock.Advance(time.Second)
+    if tree.Exists(newKey(IntKey(2))) || !tree.Exists(newKey(IntKey(1))) {
+        t.Fatalf("expiry before the unix epoch not applied")
+    }
+    clock.Advance(time.Second)
+    if tree.Exists(newKey(IntKey(1))) {
+        t.Fatalf("entry expiring at the unix epoch exists")
+    }
+    if count := tree.Count(); count != 1 || !tree.Exists(newKey(IntKey(3))) {
+        t.Fatalf("Count() = %d, want only the entry without expiry", count)
+    }
+}
+
+func TestTTLKeyTypeMismatch(t *testing.T) {
+    clock := &fakeClock{now: time.Unix(1000, 0)}
+    tree := NewTTLRbTreeWithClock(clock.Now)
+    tree.InsertWithTTL(newKey(IntKey(1)), 1, time.Second)
+
+    if err := tree.TryInsertWithTTL(newKey(StringKey("1")), 1, time.Second); err != ErrKeyTypeMismatch {
+        t.Fatalf("TryInsertWithTTL of *StringKey = %v, want ErrKeyTypeMismatch", err)
+    }
+    if err := tree.TryInsert(nil, 1); err == nil {
+        t.Fatalf("TryInsert(nil) succeeded")
+    }
+    if tree.Exists(newKey(StringKey("1"))) {
+        t.Fatalf("*StringKey found in a tree of *IntKey")
+    }
+    tree.Delete(newKey(StringKey("1")))
+    if count := tree.Count(); count != 1 {
+        t.Fatalf("Count() = %d after the rejected key, want 1", count)
+    }
+
+    func() {
+        defer func() {
+            if r := recover(); r != ErrKeyTypeMismatch {
+                t.Fatalf("Insert of *StringKey recovered %v, want ErrKeyTypeMismatch", r)
+            }
+        }()
+        tree.Insert(newKey(StringKey("2")), 2)
+    }()
+
+    // the tree accepts another key type once all the keys expired
+    clock.Advance(time.Second)
+    if err := tree.TryInsertWithTTL(newKey(StringKey("1")), 1, time.Second); err != nil {
+        t.Fatalf("TryInsertWithTTL of *StringKey in an expired tree = %v", err)
+    }
+}
+
+func TestTTLConcurrentReaperStart(t *testing.T) {
+    tree := NewTTLRbTree()
+    goroutines := runtime.NumGoroutine()
+    var wg sync.WaitGroup
+    for i := 0; i < 8; i++ {
+        wg.Add(1)
+        go func(i int) {
+            defer wg.Done()
+            for j := 0; j < 50; j++ {
+                if (i + j) % 5 == 0 {
+                    tree.StopReaper()
+                } else {
+                    tree.StartReaper(time.Millisecond)
+                }
+            }
+        }(i)
+    }
+    wg.Wait()
+    tree.StopReaper()
+
+    // a reaper lost by the concurrent restarts would never exit
+    deadline := time.Now().Add(5 * time.Second)
+    for runtime.NumGoroutine() > goroutines {
+        if time.Now().After(deadline) {
+            t.Fatalf("%d goroutines running after StopReaper, want %d", runtime.NumGoroutine(), goroutines)
+        }
+        time.Sleep(time.Millisecond)
+    }
+}
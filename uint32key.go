@@ -3,15 +3,12 @@ package rbt
 // Uint32Key is the uint32 key for RbKey
 type Uint32Key uint32
 
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *Uint32Key) ComparedTo(key RbKey) KeyComparison {
-    diff := int64(*ikey) - int64(*key.(*Uint32Key))
-    switch {
-    case diff > zeroInt64:
-        return KeyIsGreater
-    case diff < zeroInt64:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Uint32Key); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
@@ -3,16 +3,12 @@ package rbt
 // Uint64Key is the uint64 key for RbKey
 type Uint64Key uint64
 
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *Uint64Key) ComparedTo(key RbKey) KeyComparison {
-    key1 := uint64(*ikey)
-    key2 := uint64(*key.(*Uint64Key))
-    switch {
-    case key1 > key2:
-        return KeyIsGreater
-    case key1 < key2:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*Uint64Key); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
@@ -3,17 +3,12 @@ package rbt
 // UintKey is the uint key for RbKey
 type UintKey uint
 
-const zeroInt64 = int64(0)
-
-// ComparedTo compares the given RbKey with its self
+// ComparedTo compares the given RbKey with its self,
+// the numeric keys of the other types are compared by their exact values
 func (ikey *UintKey) ComparedTo(key RbKey) KeyComparison {
-    diff := int64(*ikey) - int64(*key.(*UintKey))
-    switch {
-    case diff > zeroInt64:
-        return KeyIsGreater
-    case diff < zeroInt64:
-        return KeyIsLess
-    default:
-        return KeysAreEqual
+    if other, ok := key.(*UintKey); ok {
+        return compareOrdered(*ikey, *other)
     }
-}
\ No newline at end of file
+    a, _ := numberOf(ikey)
+    return compareNumericKey(a, key)
+}
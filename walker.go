@@ -0,0 +1,50 @@
+package rbt
+
+import (
+    "bytes"
+)
+
+// rbWalker structure holds the bounds of an in order walk on a tree, a nil bound leaves that side
+// of the range open and a non nil prefix ends the walk on the first BytesKey key not starting with it.
+// The walks are recursive as an explicit stack measured slower, their depth is limited by the height of the tree.
+type rbWalker struct {
+    lo, hi *rbBound
+    descending bool
+    compare KeyComparator
+    prefix []byte
+}
+
+// aboveLo checks if the key is inside the lower bound of the walk
+func (walker *rbWalker) aboveLo(key RbKey) bool {
+    if walker.lo == nil {
+        return true
+    }
+    cmp := compareKeys(walker.compare, key, walker.lo.key)
+    return cmp == KeyIsGreater || (walker.lo.inclusive && cmp == KeysAreEqual)
+}
+
+// belowHi checks if the key is inside the upper bound of the walk
+func (walker *rbWalker) belowHi(key RbKey) bool {
+    if walker.hi == nil {
+        return true
+    }
+    cmp := compareKeys(walker.compare, key, walker.hi.key)
+    return cmp == KeyIsLess || (walker.hi.inclusive && cmp == KeysAreEqual)
+}
+
+// hasPrefix checks if the BytesKey key starts with the prefix of the walk, true if the walk has no prefix
+func (walker *rbWalker) hasPrefix(key RbKey) bool {
+    return walker.prefix == nil || bytes.HasPrefix(*key.(*BytesKey), walker.prefix)
+}
+
+// children returns the children of the node in the order of the walk, and whether the node is inside
+// the bound on the side of the first child and on the side of the last child
+func (walker *rbWalker) children(node *rbNode) (first, last *rbNode, inFirst, inLast bool) {
+    inLo := walker.lo == nil || walker.aboveLo(node.key)
+    inHi := walker.hi == nil || walker.belowHi(node.key)
+    if walker.descending {
+        return node.right, node.left, inHi, inLo
+    }
+    return node.left, node.right, inLo, inHi
+}
+